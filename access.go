@@ -0,0 +1,61 @@
+package env
+
+import (
+	"fmt"
+	"time"
+)
+
+// SecretAccess is an audit event describing the resolution of a secret from
+// a remote source. It never holds the value of the secret.
+type SecretAccess struct {
+	Time time.Time `json:"time"`
+	// Actor identifies who resolved the secret, see WithSecretAccessLog
+	Actor string `json:"actor"`
+	// Key is the variable of the secret
+	Key string `json:"key"`
+	// Source is the name of the source, SourceEnvironment for the Lookuper
+	// or the name given to WithSource, and Lookuper its type
+	Source   string `json:"source"`
+	Lookuper string `json:"lookuper"`
+	// Found is whether the source holds the secret
+	Found bool `json:"found"`
+	// Error is the error of the lookup, if any
+	Error string `json:"error,omitempty"`
+}
+
+// SecretAccessLog receives SecretAccess events, e.g. to write them to an
+// audit trail. It is called synchronously during Parse.
+type SecretAccessLog func(SecretAccess)
+
+// WithSecretAccessLog emits an audit event to log every time a secret field
+// is looked up from a remote source, i.e. a ContextLookuper, as required by
+// compliance in regulated environments. actor identifies the application,
+// e.g. its service account.
+func WithSecretAccessLog(actor string, log SecretAccessLog) Option {
+	return func(o *options) {
+		o.accessActor, o.accessLog = actor, log
+	}
+}
+
+// logSecretAccess emits the audit event of the lookup of the field described
+// by info from the source src named name, if it is a remote secret lookup
+func (o *options) logSecretAccess(info tagInfo, name string, src Lookuper, ok bool, err error) {
+	if o.accessLog == nil || !info.secret {
+		return
+	}
+	if _, remote := src.(ContextLookuper); !remote {
+		return
+	}
+	event := SecretAccess{
+		Time:     time.Now(),
+		Actor:    o.accessActor,
+		Key:      info.key,
+		Source:   name,
+		Lookuper: fmt.Sprintf("%T", src),
+		Found:    ok,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	o.accessLog(event)
+}
@@ -0,0 +1,74 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// remoteMap is a Map behaving like a remote store
+type remoteMap env.Map
+
+func (m remoteMap) Lookup(key string) (string, bool) {
+	v, ok, _ := m.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (m remoteMap) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if key == "BROKEN" {
+		return "", false, errors.New("access denied")
+	}
+	v, ok := m[key]
+	return v, ok, nil
+}
+
+func TestSecretAccessLog(t *testing.T) {
+	type config struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD,secret"`
+		APIKey   string `env:"API_KEY,secret"`
+		Token    string `env:"TOKEN,secret"`
+	}
+	var events []env.SecretAccess
+	log := env.WithSecretAccessLog("payments-api", func(e env.SecretAccess) {
+		assert.False(t, e.Time.IsZero())
+		events = append(events, e)
+	})
+	cfg := config{}
+	err := env.Parse(&cfg, log,
+		env.WithLookuper(remoteMap{"HOST": "db", "PASSWORD": "s3cr3t"}),
+		env.WithSource("local", env.Map{"API_KEY": "local"}),
+		env.WithSource("vault", remoteMap{"TOKEN": "t0k3n"}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, config{Host: "db", Password: "s3cr3t", APIKey: "local", Token: "t0k3n"}, cfg)
+	assert.Len(t, events, 4)
+	want := []struct {
+		key, source string
+		found       bool
+	}{
+		{"PASSWORD", env.SourceEnvironment, true},
+		{"API_KEY", env.SourceEnvironment, false},
+		{"TOKEN", env.SourceEnvironment, false},
+		{"TOKEN", "vault", true},
+	}
+	for i, w := range want {
+		assert.Equal(t, "payments-api", events[i].Actor)
+		assert.Equal(t, w.key, events[i].Key)
+		assert.Equal(t, w.source, events[i].Source)
+		assert.Equal(t, w.found, events[i].Found)
+		assert.Equal(t, "env_test.remoteMap", events[i].Lookuper)
+	}
+
+	events = nil
+	type broken struct {
+		Secret string `env:"BROKEN,secret"`
+	}
+	err = env.Parse(&broken{}, log, env.WithLookuper(remoteMap{}))
+	assert.EqualError(t, err, "access denied")
+	assert.Len(t, events, 1)
+	assert.Equal(t, "access denied", events[0].Error)
+}
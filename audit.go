@@ -0,0 +1,144 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// WithNamespaceAudit reports, after parsing, any variable starting with
+// prefix that no struct field reads, catching typos such as
+// `MYAPP_TIMEOTU=5s` that would otherwise silently do nothing. It requires
+// a Lookuper that implements KeyLister, as the process environment does;
+// other Lookupers are not audited. Unknown variables are errors, or
+// warnings with `WithResult`.
+func WithNamespaceAudit(prefix string) Option {
+	return func(o *options) {
+		o.audit = append(o.audit, prefix)
+	}
+}
+
+func (o *options) auditNamespaces() error {
+	if len(o.audit) == 0 || o.only != nil {
+		return nil
+	}
+	lister, ok := o.lookuper.(KeyLister)
+	if !ok {
+		return nil
+	}
+	var errorList []string
+	for _, key := range listKeys(lister) {
+		if _, ok := o.consumed[key]; ok {
+			continue
+		}
+		for _, prefix := range o.audit {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if o.result != nil {
+				o.warn("", key, "Unknown environment variable "+key)
+			} else {
+				errorList = append(errorList, "Unknown environment variable "+key)
+			}
+			break
+		}
+	}
+	if len(errorList) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errorList, ". "))
+}
+
+// WithDuplicateKeyCheck makes Parse fail when two fields read the same
+// environment variable, which is usually a mistake and confusing when the
+// fields have different types
+func WithDuplicateKeyCheck() Option {
+	return func(o *options) {
+		o.dupCheck = true
+	}
+}
+
+// bind records that the field at fieldPath reads key
+func (o *options) bind(key, fieldPath string) error {
+	if other, ok := o.consumed[key]; ok && o.dupCheck && other != fieldPath {
+		return errors.New("Environment variable " + key + " is read by both " + other + " and " + fieldPath)
+	}
+	o.consumed[key] = fieldPath
+	return nil
+}
+
+// WithTagsCheck makes Parse fail when no field of the struct, or of the
+// structs nested in it, has an env tag, which usually means the wrong value
+// was passed or the tags were forgotten, and would otherwise silently do
+// nothing
+func WithTagsCheck() Option {
+	return func(o *options) {
+		o.tagsCheck = true
+	}
+}
+
+// checkTags returns an error if the tags check is enabled and the struct
+// type t reads no variable
+func (o *options) checkTags(t reflect.Type) error {
+	if !o.tagsCheck || o.hasTags(t, "", 0) {
+		return nil
+	}
+	return errors.New("Struct " + t.String() + " has no field with an env tag")
+}
+
+// hasTags reports whether an exported field of the struct type t, or of the
+// structs nested in it, reads a variable or has a default
+func (o *options) hasTags(t reflect.Type, path string, depth int) bool {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		info, err := o.fieldInfo(sf, path+sf.Name)
+		// invalid tags are reported by Parse
+		if err != nil || info.key != "" || info.defaultValue != "" {
+			return true
+		}
+		nested := sf.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if depth < o.maxDepth && isStruct(nested, o.converters) && o.hasTags(nested, path+sf.Name+".", depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithForbidDefaults makes Parse fail when a secret field, or a field
+// reading one of keys, falls back to its `envDefault`, so default
+// credentials or localhost endpoints can't be shipped to production by
+// accident. The keys are the full keys, prefixes included.
+func WithForbidDefaults(keys ...string) Option {
+	return func(o *options) {
+		if o.forbidDefaults == nil {
+			o.forbidDefaults = map[string]bool{}
+		}
+		for _, key := range keys {
+			o.forbidDefaults[key] = true
+		}
+	}
+}
+
+// checkFallback returns an error if the field described by info, tagged
+// with `envOnError:"default"`, can't fall back to its default
+func (o *options) checkFallback(info tagInfo) error {
+	if info.onError != onErrorDefault || info.defaultValue == "" {
+		return nil
+	}
+	return o.checkDefault(info)
+}
+
+// checkDefault returns an error if the field described by info can't use
+// its default
+func (o *options) checkDefault(info tagInfo) error {
+	if o.forbidDefaults == nil || (!info.secret && !o.forbidDefaults[info.key]) {
+		return nil
+	}
+	return errors.New("Environment variable " + info.key + " must be set, its default is forbidden")
+}
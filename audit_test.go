@@ -0,0 +1,122 @@
+package env_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceAudit(t *testing.T) {
+	type config struct {
+		Timeout string `env:"MYAPP_TIMEOUT"`
+		Port    int    `env:"MYAPP_PORT"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithNamespaceAudit("MYAPP_"), env.WithLookuper(env.Map{
+		"MYAPP_TIMEOTU": "5s",
+		"MYAPP_PORT":    "80",
+		"MYAPP_HOST":    "localhost",
+		"OTHER":         "value",
+	}))
+	assert.EqualError(t, err, "Unknown environment variable MYAPP_HOST. Unknown environment variable MYAPP_TIMEOTU")
+	assert.Equal(t, 80, cfg.Port)
+}
+
+func TestNamespaceAuditOSEnv(t *testing.T) {
+	type config struct {
+		Timeout string `env:"MYAPP_TIMEOUT"`
+	}
+	os.Setenv("MYAPP_TIMEOUT", "5s")
+	defer os.Clearenv()
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithNamespaceAudit("MYAPP_")))
+
+	os.Setenv("MYAPP_TIMEOTU", "5s")
+	assert.EqualError(t, env.Parse(&cfg, env.WithNamespaceAudit("MYAPP_")), "Unknown environment variable MYAPP_TIMEOTU")
+}
+
+func TestNamespaceAuditWithFieldErrors(t *testing.T) {
+	type config struct {
+		Port int `env:"MYAPP_PORT"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithNamespaceAudit("MYAPP_"), env.WithLookuper(env.Map{
+		"MYAPP_PORT": "eighty",
+		"MYAPP_PROT": "80",
+	}))
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "eighty": invalid syntax. Unknown environment variable MYAPP_PROT`)
+}
+
+func TestDuplicateKeyCheck(t *testing.T) {
+	type server struct {
+		Port string `env:"PORT"`
+	}
+	type config struct {
+		Port   int    `env:"PORT"`
+		Host   string `env:"HOST"`
+		Server *server
+	}
+	cfg := config{Server: &server{}}
+	lookuper := env.WithLookuper(env.Map{"PORT": "80"})
+	assert.NoError(t, env.Parse(&cfg, lookuper))
+	assert.EqualError(t, env.Parse(&cfg, lookuper, env.WithDuplicateKeyCheck()), "Environment variable PORT is read by both Port and Server.Port")
+}
+
+func TestTagsCheck(t *testing.T) {
+	type untagged struct {
+		Host string
+		Port int
+		tag  string `env:"TAG"`
+	}
+	assert.EqualError(t, env.Parse(&untagged{}, env.WithTagsCheck()), "Struct env_test.untagged has no field with an env tag")
+	assert.NoError(t, env.Parse(&untagged{}))
+
+	type nested struct {
+		Name     string
+		Upstream *upstream
+	}
+	cfg := nested{}
+	err := env.Parse(&cfg, env.WithTagsCheck(), env.WithLookuper(env.Map{"HOST": "localhost"}))
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.Upstream)
+
+	type defaults struct {
+		Region string `envDefault:"eu"`
+	}
+	assert.NoError(t, env.Parse(&defaults{}, env.WithTagsCheck()))
+}
+
+func TestForbidDefaults(t *testing.T) {
+	type config struct {
+		Password string `env:"DB_PASSWORD,secret" envDefault:"postgres"`
+		Host     string `env:"DB_HOST" envDefault:"localhost"`
+		Port     int    `env:"DB_PORT" envDefault:"5432"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{}), env.WithForbidDefaults("DB_HOST"))
+	assert.EqualError(t, err, "Environment variable DB_PASSWORD must be set, its default is forbidden. Environment variable DB_HOST must be set, its default is forbidden")
+	assert.Equal(t, 5432, cfg.Port)
+
+	cfg = config{}
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"DB_PASSWORD": "s3cr3t", "DB_HOST": "db"}), env.WithForbidDefaults("DB_HOST"))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Password: "s3cr3t", Host: "db", Port: 5432}, cfg)
+
+	cfg = config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})))
+	assert.Equal(t, "postgres", cfg.Password)
+}
+
+func TestForbidDefaultsOnError(t *testing.T) {
+	type config struct {
+		Host    string `env:"DB_HOST" envDefault:"localhost" envOneOf:"db,replica" envOnError:"default"`
+		Port    int    `env:"DB_PORT" envDefault:"5432" envOnError:"default"`
+		Retries int    `env:"RETRIES" envDefault:"3" envOnError:"ignore"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"DB_HOST": "localhost", "DB_PORT": "x", "RETRIES": "x"}), env.WithForbidDefaults("DB_HOST"))
+	assert.EqualError(t, err, "Invalid value for DB_HOST, expected one of db, replica. Environment variable DB_HOST must be set, its default is forbidden")
+	assert.Equal(t, config{Port: 5432}, cfg)
+}
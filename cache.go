@@ -0,0 +1,89 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache is a Lookuper that remembers the values returned by another Lookuper
+// for a while, so repeated parses don't hammer remote stores. Failed lookups
+// are not cached.
+type Cache struct {
+	// OnHit and OnMiss, if set, are called on every lookup served from the
+	// cache or from the underlying Lookuper respectively, e.g. to count them.
+	// They must be set before the Cache is used.
+	OnHit  func(key string)
+	OnMiss func(key string)
+
+	src     Lookuper
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   string
+	ok      bool
+	expires time.Time
+}
+
+// Cached returns a Cache that keeps the values looked up from src for ttl
+func Cached(src Lookuper, ttl time.Duration) *Cache {
+	return &Cache{
+		src:     src,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Lookup returns the cached value for key, looking it up if it is missing or
+// expired
+func (c *Cache) Lookup(key string) (string, bool) {
+	value, ok, _ := c.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+// LookupContext is the same as Lookup, passing ctx to the underlying Lookuper
+// if it is a ContextLookuper
+func (c *Cache) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.mu.Unlock()
+	if found && time.Now().Before(entry.expires) {
+		if c.OnHit != nil {
+			c.OnHit(key)
+		}
+		return entry.value, entry.ok, nil
+	}
+	if c.OnMiss != nil {
+		c.OnMiss(key)
+	}
+
+	var (
+		value string
+		ok    bool
+		err   error
+	)
+	if l, isCtx := c.src.(ContextLookuper); isCtx {
+		value, ok, err = l.LookupContext(ctx, key)
+	} else {
+		value, ok = c.src.Lookup(key)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{value: value, ok: ok, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, ok, nil
+}
+
+// Invalidate removes key from the cache, so the next lookup goes to the
+// underlying Lookuper
+func (c *Cache) Invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
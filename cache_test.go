@@ -0,0 +1,69 @@
+package env_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLookuper struct {
+	env.Map
+	calls int
+}
+
+func (l *countingLookuper) Lookup(key string) (string, bool) {
+	l.calls++
+	return l.Map.Lookup(key)
+}
+
+func TestCached(t *testing.T) {
+	type config struct {
+		Var     string `env:"VAR"`
+		Missing string `env:"MISSING"`
+	}
+	src := &countingLookuper{Map: env.Map{"VAR": "value"}}
+	var hits, misses int
+	cache := env.Cached(src, time.Hour)
+	cache.OnHit = func(string) { hits++ }
+	cache.OnMiss = func(string) { misses++ }
+
+	for i := 0; i < 3; i++ {
+		cfg := config{}
+		assert.NoError(t, env.Parse(&cfg, env.WithLookuper(cache)))
+		assert.Equal(t, "value", cfg.Var)
+	}
+	assert.Equal(t, 2, src.calls)
+	assert.Equal(t, 4, hits)
+	assert.Equal(t, 2, misses)
+
+	src.Map["VAR"] = "changed"
+	cache.Invalidate("VAR")
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(cache)))
+	assert.Equal(t, "changed", cfg.Var)
+	assert.Equal(t, 3, src.calls)
+}
+
+func TestCachedExpires(t *testing.T) {
+	src := &countingLookuper{Map: env.Map{"VAR": "value"}}
+	cache := env.Cached(src, time.Millisecond)
+	cache.Lookup("VAR")
+	time.Sleep(5 * time.Millisecond)
+	v, ok := cache.Lookup("VAR")
+	assert.True(t, ok)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 2, src.calls)
+}
+
+func TestCachedDoesNotCacheErrors(t *testing.T) {
+	src := &flakyLookuper{failures: 1}
+	cache := env.Cached(src, time.Hour)
+	_, _, err := cache.LookupContext(context.Background(), "VAR")
+	assert.Error(t, err)
+	v, ok := cache.Lookup("VAR")
+	assert.True(t, ok)
+	assert.Equal(t, "value", v)
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// isWildcard reports whether key is a pattern, such as `FEATURE_*`, rather
+// than the name of a variable
+func isWildcard(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// parseWildcard fills field, a map, with every variable whose name matches
+// the pattern info.key, keyed by name
+func parseWildcard(field reflect.Value, info tagInfo, fieldPath string, o *options) error {
+	t := field.Type()
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return errors.New("Environment variable pattern " + info.key + " requires a map with string keys")
+	}
+	if _, err := path.Match(info.key, ""); err != nil {
+		return errors.New("Invalid environment variable pattern " + info.key)
+	}
+	lister, ok := o.lookuper.(KeyLister)
+	if !ok {
+		return errors.New("Environment variable pattern " + info.key + " requires a Lookuper that lists its keys")
+	}
+
+	c := o.conversion(info)
+	m := reflect.MakeMap(t)
+	for _, key := range listKeys(lister) {
+		if ok, _ := path.Match(info.key, key); !ok {
+			continue
+		}
+		if err := o.bind(key, fieldPath); err != nil {
+			return err
+		}
+		value, ok, err := o.lookup(key, info.timeout)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if info.trim || o.trim {
+			value = trim(value)
+		}
+		v, err := convert(value, t.Elem(), c)
+		if err != nil {
+			return err
+		}
+		name := key
+		if info.stripPrefix {
+			name = strings.TrimPrefix(name, literalPrefix(info.key))
+		}
+		m.SetMapIndex(reflect.ValueOf(info.normalize(name)).Convert(t.Key()), v)
+		o.fieldParsed(key)
+	}
+	if m.Len() == 0 {
+		if info.required {
+			o.requiredMissing(info.key)
+			return errors.New("Required environment variable " + info.key + " is not set")
+		}
+		return nil
+	}
+	field.Set(m)
+	return nil
+}
+
+// literalPrefix returns the part of pattern before its first special
+// character
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// normalize applies the field's normalization options to a captured key
+func (info tagInfo) normalize(key string) string {
+	if info.dotted {
+		key = strings.Replace(key, "_", ".", -1)
+	}
+	if info.lowercase {
+		key = strings.ToLower(key)
+	}
+	return key
+}
@@ -0,0 +1,55 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWildcard(t *testing.T) {
+	type config struct {
+		Features map[string]bool   `env:"FEATURE_*"`
+		Labels   map[string]string `env:"LABEL_?"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"FEATURE_SEARCH": "true",
+		"FEATURE_CHAT":   "false",
+		"LABEL_A":        "a",
+		"LABEL_BB":       "ignored",
+		"OTHER":          "ignored",
+	})))
+	assert.Equal(t, map[string]bool{"FEATURE_SEARCH": true, "FEATURE_CHAT": false}, cfg.Features)
+	assert.Equal(t, map[string]string{"LABEL_A": "a"}, cfg.Labels)
+}
+
+func TestWildcardNormalization(t *testing.T) {
+	type config struct {
+		Features map[string]bool   `env:"FEATURE_*,stripPrefix,lowercase,dotted"`
+		Labels   map[string]string `env:"LABEL_*,stripPrefix"`
+		Raw      map[string]string `env:"LABEL_*,lowercase"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"FEATURE_NEW_SEARCH": "true",
+		"LABEL_TEAM":         "core",
+	})))
+	assert.Equal(t, map[string]bool{"new.search": true}, cfg.Features)
+	assert.Equal(t, map[string]string{"TEAM": "core"}, cfg.Labels)
+	assert.Equal(t, map[string]string{"label_team": "core"}, cfg.Raw)
+}
+
+func TestWildcardErrors(t *testing.T) {
+	type config struct {
+		Features map[string]bool `env:"FEATURE_*,required"`
+	}
+	cfg := config{}
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})), "Required environment variable FEATURE_* is not set")
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"FEATURE_A": "maybe"})), `strconv.ParseBool: parsing "maybe": invalid syntax`)
+
+	type badConfig struct {
+		Features string `env:"FEATURE_*"`
+	}
+	assert.EqualError(t, env.Parse(&badConfig{}, env.WithLookuper(env.Map{})), "Environment variable pattern FEATURE_* requires a map with string keys")
+}
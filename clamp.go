@@ -0,0 +1,124 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseClamp parses `envClamp`, the bounds `min:max` of the field of type t,
+// either of which can be left out, e.g. `1:` has no upper bound
+func parseClamp(clamp string, t reflect.Type) (string, string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	invalid := errors.New("Invalid envClamp " + clamp + ", expected min:max")
+	if !isNumber(t) {
+		return "", "", errors.New("Invalid envClamp " + clamp + ", the field isn't a number")
+	}
+	lo, hi, ok := strings.Cut(clamp, ":")
+	if !ok || lo == "" && hi == "" {
+		return "", "", invalid
+	}
+	for _, bound := range []string{lo, hi} {
+		if bound == "" {
+			continue
+		}
+		if _, err := parseNumber(bound, t); err != nil {
+			return "", "", invalid
+		}
+	}
+	if lo != "" && hi != "" && compareNumbers(mustParseNumber(lo, t), mustParseNumber(hi, t), t) > 0 {
+		return "", "", errors.New("Invalid envClamp " + clamp + ", expected min <= max")
+	}
+	return lo, hi, nil
+}
+
+// clamp brings the number in field, parsed from value, within the bounds of
+// `envClamp`. It returns a warning when the value was out of bounds.
+func (info tagInfo) clamp(field reflect.Value, value string) error {
+	if info.clampMin == "" && info.clampMax == "" {
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	t := field.Type()
+	bound := ""
+	if info.clampMin != "" && compareNumbers(field, mustParseNumber(info.clampMin, t), t) < 0 {
+		bound = info.clampMin
+	}
+	if info.clampMax != "" && compareNumbers(field, mustParseNumber(info.clampMax, t), t) > 0 {
+		bound = info.clampMax
+	}
+	if bound == "" {
+		return nil
+	}
+	field.Set(mustParseNumber(bound, t))
+	return errors.New("Value " + value + " of " + info.key + " is out of range " + info.clampMin + ":" + info.clampMax + ", clamped to " + bound)
+}
+
+// isNumber reports whether t is an integer or a float type, durations
+// excluded
+func isNumber(t reflect.Type) bool {
+	if t == durationType {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseNumber parses s as a value of t, a number type
+func parseNumber(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+		return v, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		v.SetUint(u)
+		return v, err
+	default:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		v.SetInt(i)
+		return v, err
+	}
+}
+
+// mustParseNumber parses s, a bound validated by parseClamp, as a value of t
+func mustParseNumber(s string, t reflect.Type) reflect.Value {
+	v, _ := parseNumber(s, t)
+	return v
+}
+
+// compareNumbers returns -1, 0 or 1 as a is less than, equal to or greater
+// than b, two values of t, a number type
+func compareNumbers(a, b reflect.Value, t reflect.Type) int {
+	var less, greater bool
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		less, greater = a.Float() < b.Float(), a.Float() > b.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less, greater = a.Uint() < b.Uint(), a.Uint() > b.Uint()
+	default:
+		less, greater = a.Int() < b.Int(), a.Int() > b.Int()
+	}
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
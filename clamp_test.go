@@ -0,0 +1,58 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClamp(t *testing.T) {
+	type config struct {
+		Port    uint     `env:"PORT" envClamp:"1:65535"`
+		Workers int      `env:"WORKERS" envClamp:"1:64"`
+		Ratio   *float64 `env:"RATIO" envClamp:":1"`
+		Retries int      `env:"RETRIES" envDefault:"-1" envClamp:"0:"`
+	}
+
+	var cfg config
+	r, err := env.ParseWithResult(&cfg, env.WithLookuper(env.Map{
+		"PORT":    "0",
+		"WORKERS": "100",
+		"RATIO":   "1.5",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), cfg.Port)
+	assert.Equal(t, 64, cfg.Workers)
+	assert.Equal(t, 1.0, *cfg.Ratio)
+	assert.Equal(t, 0, cfg.Retries)
+	assert.Equal(t, []env.Warning{
+		{Field: "Port", Key: "PORT", Message: "Value 0 of PORT is out of range 1:65535, clamped to 1"},
+		{Field: "Workers", Key: "WORKERS", Message: "Value 100 of WORKERS is out of range 1:64, clamped to 64"},
+		{Field: "Ratio", Key: "RATIO", Message: "Value 1.5 of RATIO is out of range :1, clamped to 1"},
+		{Field: "Retries", Key: "RETRIES", Message: "Value -1 of RETRIES is out of range 0:, clamped to 0"},
+	}, r.Warnings)
+
+	r, err = env.ParseWithResult(&cfg, env.WithLookuper(env.Map{"PORT": "8080", "WORKERS": "8", "RETRIES": "3"}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8080), cfg.Port)
+	assert.Equal(t, 8, cfg.Workers)
+	assert.Empty(t, r.Warnings)
+}
+
+func TestClampInvalid(t *testing.T) {
+	type bounds struct {
+		Port int `env:"PORT" envClamp:"10:1"`
+	}
+	type invalid struct {
+		Port int `env:"PORT" envClamp:"1:x"`
+	}
+	type text struct {
+		Name string `env:"NAME" envClamp:"1:2"`
+	}
+	empty := env.Map{}
+	assert.Equal(t, errors.New("Invalid envClamp 10:1, expected min <= max"), env.Parse(&bounds{}, env.WithLookuper(empty)))
+	assert.Equal(t, errors.New("Invalid envClamp 1:x, expected min:max"), env.Parse(&invalid{}, env.WithLookuper(empty)))
+	assert.Equal(t, errors.New("Invalid envClamp 1:2, the field isn't a number"), env.Parse(&text{}, env.WithLookuper(empty)))
+}
@@ -0,0 +1,95 @@
+// Command envcheck validates an environment against a spec of the variables
+// an application expects, as produced by `env.Describe` or `env.JSONSchema`:
+//
+//	envcheck -spec spec.json [-env-file .env] [-prefix MYAPP_]
+//
+// It reports missing required variables, values that cannot be parsed and,
+// when -prefix is given, variables with that prefix not declared in the
+// spec. It exits with status 1 if any problem is found, so it can be used in
+// CI pipelines and entrypoint scripts.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/caarlos0/env"
+)
+
+func main() {
+	var (
+		specPath = flag.String("spec", "", "path to the JSON spec of the expected variables")
+		envFile  = flag.String("env-file", "", "check this .env file instead of the current environment")
+		prefix   = flag.String("prefix", "", "report variables with this prefix not declared in the spec")
+	)
+	flag.Parse()
+	if *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	vars, err := readSpec(*specPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "envcheck:", err)
+		os.Exit(2)
+	}
+
+	environ := env.Map{}
+	if *envFile != "" {
+		if environ, err = env.LoadDotEnv(*envFile); err != nil {
+			fmt.Fprintln(os.Stderr, "envcheck:", err)
+			os.Exit(2)
+		}
+	} else {
+		for _, kv := range os.Environ() {
+			parts := strings.SplitN(kv, "=", 2)
+			environ[parts[0]] = parts[1]
+		}
+	}
+
+	problems := env.Check(vars, environ)
+	if *prefix != "" {
+		problems = append(problems, unknown(vars, environ, *prefix)...)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func readSpec(path string) ([]env.Var, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vars, err := env.ReadSpec(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %v", path, err)
+	}
+	return vars, nil
+}
+
+func unknown(vars []env.Var, environ env.Map, prefix string) []error {
+	declared := map[string]bool{}
+	for _, v := range vars {
+		declared[v.Key] = true
+	}
+	var keys []string
+	for k := range environ {
+		if strings.HasPrefix(k, prefix) && !declared[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		errs = append(errs, fmt.Errorf("Unknown environment variable %s", k))
+	}
+	return errs
+}
@@ -0,0 +1,294 @@
+// Command envdoc scans the Go package in a directory for structs with `env`
+// tags and documents the variables they read, as Markdown or JSON:
+//
+//	envdoc [-type Config] [-format markdown|json|jsonschema] [-init .env] [dir]
+//
+// Field doc comments become the variables' descriptions. The JSON output of
+// a single type (-type) is a list of `env.Var`, the same format returned by
+// `env.Describe`, so it can be used as a spec for envcheck. The jsonschema
+// output is the JSON Schema returned by `env.JSONSchema`. With -init, the
+// variables missing from the given .env file are added to it, set to their
+// defaults, instead.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/env"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "only document this struct type")
+		format   = flag.String("format", "markdown", "output format: markdown, json or jsonschema")
+		initPath = flag.String("init", "", "add the variables missing from this .env file to it")
+	)
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	structs, err := scan(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "envdoc:", err)
+		os.Exit(1)
+	}
+	if *typeName != "" {
+		var found []structDoc
+		for _, s := range structs {
+			if s.name == *typeName {
+				found = append(found, s)
+			}
+		}
+		if len(found) == 0 {
+			fmt.Fprintf(os.Stderr, "envdoc: no struct type %s with env tags in %s\n", *typeName, dir)
+			os.Exit(1)
+		}
+		structs = found
+	}
+
+	if *initPath != "" {
+		if err := initDotEnv(*initPath, structs); err != nil {
+			fmt.Fprintln(os.Stderr, "envdoc:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	switch *format {
+	case "markdown":
+		writeMarkdown(os.Stdout, structs)
+	case "json":
+		err = writeJSON(os.Stdout, structs, *typeName != "")
+	case "jsonschema":
+		err = writeJSONSchema(os.Stdout, structs, *typeName != "")
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "envdoc:", err)
+		os.Exit(1)
+	}
+}
+
+type structDoc struct {
+	name string
+	vars []env.Var
+}
+
+func scan(dir string) ([]structDoc, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	structTypes := map[string]*ast.StructType{}
+	var names []string
+	for _, pkg := range pkgs {
+		var files []string
+		for name := range pkg.Files {
+			files = append(files, name)
+		}
+		sort.Strings(files)
+		for _, name := range files {
+			ast.Inspect(pkg.Files[name], func(n ast.Node) bool {
+				spec, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structTypes[spec.Name.Name] = st
+					names = append(names, spec.Name.Name)
+				}
+				return true
+			})
+		}
+	}
+
+	var structs []structDoc
+	for _, name := range names {
+		vars, err := describe(structTypes, structTypes[name], "", "", map[string]bool{name: true})
+		if err != nil {
+			return nil, errors.New(name + "." + err.Error())
+		}
+		if hasOwnTags(structTypes[name]) {
+			structs = append(structs, structDoc{name: name, vars: vars})
+		}
+	}
+	return structs, nil
+}
+
+// describe lists the variables of st, reading its tags with env.ParseTag
+// as Parse does. The types of the fields are unknown, so the tags aren't
+// checked against them.
+func describe(structTypes map[string]*ast.StructType, st *ast.StructType, path, prefix string, seen map[string]bool) ([]env.Var, error) {
+	var vars []env.Var
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			info, err := env.ParseTag(reflect.StructField{Name: name.Name, Tag: tag})
+			if err != nil {
+				return nil, errors.New(path + name.Name + ": " + err.Error())
+			}
+			if info.Key == "" {
+				// inline structs, with no name, can't be recursive
+				if st, nested := nestedStruct(structTypes, field.Type); st != nil && (nested == "" || !seen[nested]) {
+					seen[nested] = true
+					nestedVars, err := describe(structTypes, st, path+name.Name+".", prefix+info.Prefix, seen)
+					delete(seen, nested)
+					if err != nil {
+						return nil, err
+					}
+					vars = append(vars, nestedVars...)
+				}
+				continue
+			}
+			vars = append(vars, env.Var{
+				Field:       path + name.Name,
+				Key:         prefix + info.Key,
+				Type:        types.ExprString(field.Type),
+				Default:     info.Default,
+				Required:    info.Required,
+				Secret:      info.Secret,
+				Separator:   tag.Get("envSeparator"),
+				OneOf:       info.OneOf,
+				Description: description(field),
+			})
+		}
+	}
+	return vars, nil
+}
+
+func hasOwnTags(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if info, err := env.ParseTag(reflect.StructField{Tag: fieldTag(field)}); err == nil && info.Key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+// nestedStruct returns the struct type of a `T` or `*T` field, T being a
+// local type, and its name, or of an inline struct field
+func nestedStruct(structTypes map[string]*ast.StructType, expr ast.Expr) (*ast.StructType, string) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return structTypes[t.Name], t.Name
+	case *ast.StructType:
+		return t, ""
+	}
+	return nil, ""
+}
+
+func description(field *ast.Field) string {
+	text := field.Doc.Text()
+	if text == "" {
+		text = field.Comment.Text()
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func writeJSON(w io.Writer, structs []structDoc, single bool) error {
+	enc := json.NewEncoder(w)
+	if single {
+		return enc.Encode(structs[0].vars)
+	}
+	out := map[string][]env.Var{}
+	for _, s := range structs {
+		out[s.name] = s.vars
+	}
+	return enc.Encode(out)
+}
+
+func writeJSONSchema(w io.Writer, structs []structDoc, single bool) error {
+	enc := json.NewEncoder(w)
+	if single {
+		return enc.Encode(env.SchemaOf(structs[0].vars))
+	}
+	out := map[string]*env.Schema{}
+	for _, s := range structs {
+		out[s.name] = env.SchemaOf(s.vars)
+	}
+	return enc.Encode(out)
+}
+
+func writeMarkdown(w io.Writer, structs []structDoc) {
+	for i, s := range structs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "## %s\n\n", s.name)
+		fmt.Fprintln(w, "| Variable | Type | Default | Required | Description |")
+		fmt.Fprintln(w, "|----------|------|---------|----------|-------------|")
+		for _, v := range s.vars {
+			def := ""
+			if v.Default != "" {
+				def = "`" + v.Default + "`"
+			}
+			required := ""
+			if v.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(w, "| `%s` | `%s` | %s | %s | %s |\n", v.Key, v.Type, def, required, escape(v.Description))
+		}
+	}
+}
+
+func escape(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
+
+// initDotEnv adds the variables of structs missing from the .env file at
+// path to it, set to their defaults
+func initDotEnv(path string, structs []structDoc) error {
+	existing, err := env.LoadDotEnv(path)
+	if os.IsNotExist(err) {
+		existing, err = env.Map{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	missing := env.Map{}
+	for _, s := range structs {
+		for _, v := range s.vars {
+			if _, ok := existing[v.Key]; !ok {
+				missing[v.Key] = v.Default
+			}
+		}
+	}
+	return env.UpdateDotEnv(path, missing)
+}
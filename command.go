@@ -0,0 +1,72 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandPrefix starts the `envSource` tags running a command
+const commandPrefix = "cmd:"
+
+// defaultCommandTimeout bounds the commands of fields without `envTimeout`
+const defaultCommandTimeout = 10 * time.Second
+
+// WithCommands allows the commands named, e.g. "op" or "vault", to be run by
+// fields tagged with `envSource:"cmd:..."`. Other commands are refused, so
+// struct tags can't run arbitrary programs.
+func WithCommands(names ...string) Option {
+	return func(o *options) {
+		if o.commands == nil {
+			o.commands = map[string]bool{}
+		}
+		for _, name := range names {
+			o.commands[name] = true
+		}
+	}
+}
+
+// parseSource returns the arguments of the command of an `envSource` tag
+func parseSource(source string) ([]string, error) {
+	if !strings.HasPrefix(source, commandPrefix) {
+		return nil, errors.New("Invalid envSource " + source + ", expected " + commandPrefix + "<command>")
+	}
+	args := strings.Fields(source[len(commandPrefix):])
+	if len(args) == 0 {
+		return nil, errors.New("Invalid envSource " + source + ", the command is empty")
+	}
+	return args, nil
+}
+
+// runCommand returns the output of the command of the field described by
+// info, without its trailing newline. The command is run without a shell
+// and canceled after the field's `envTimeout`, 10s by default.
+func (o *options) runCommand(info tagInfo) (string, error) {
+	name, quoted := info.command[0], strconv.Quote(strings.Join(info.command, " "))
+	if !o.commands[name] {
+		return "", errors.New("Command " + quoted + " is not allowed, see WithCommands")
+	}
+	timeout := info.timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(o.ctx, timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, info.command[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded && o.ctx.Err() == nil {
+			return "", errors.New("Command " + quoted + " timed out after " + timeout.String())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New("Command " + quoted + " failed: " + err.Error() + ": " + msg)
+		}
+		return "", errors.New("Command " + quoted + " failed: " + err.Error())
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandSource(t *testing.T) {
+	// other tests clear the environment
+	os.Setenv("PATH", "/usr/bin:/bin")
+	defer os.Clearenv()
+
+	type config struct {
+		Token   string `env:"TOKEN" envSource:"cmd:echo s3cr3t"`
+		Version string `envSource:"cmd:printf 1.2.3"`
+		Region  string `env:"REGION" envDefault:"eu" envSource:"cmd:echo us"`
+	}
+	report := env.Report{}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{}), env.WithCommands("echo", "printf"), env.WithReport(&report))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Token: "s3cr3t", Version: "1.2.3", Region: "us"}, cfg)
+	assert.Equal(t, env.SourceCommand, report.Fields[0].Source)
+
+	// the variable overrides the command
+	cfg = config{}
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"TOKEN": "override"}), env.WithCommands("echo", "printf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "override", cfg.Token)
+}
+
+func TestCommandSourceErrors(t *testing.T) {
+	os.Setenv("PATH", "/usr/bin:/bin")
+	defer os.Clearenv()
+
+	type notAllowed struct {
+		Token string `env:"TOKEN" envSource:"cmd:echo s3cr3t"`
+	}
+	err := env.Parse(&notAllowed{}, env.WithLookuper(env.Map{}), env.WithCommands("op"))
+	assert.Equal(t, errors.New(`Command "echo s3cr3t" is not allowed, see WithCommands`), err)
+
+	type failing struct {
+		Token string `env:"TOKEN" envSource:"cmd:false --quiet"`
+	}
+	err = env.Parse(&failing{}, env.WithLookuper(env.Map{}), env.WithCommands("false"))
+	assert.Equal(t, errors.New(`Command "false --quiet" failed: exit status 1`), err)
+
+	type slow struct {
+		Token string `env:"TOKEN" envSource:"cmd:sleep 5" envTimeout:"50ms"`
+	}
+	err = env.Parse(&slow{}, env.WithLookuper(env.Map{}), env.WithCommands("sleep"))
+	assert.Equal(t, errors.New(`Command "sleep 5" timed out after 50ms`), err)
+
+	type invalid struct {
+		Token string `env:"TOKEN" envSource:"file:token.txt"`
+	}
+	err = env.Parse(&invalid{}, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Invalid envSource file:token.txt, expected cmd:<command>"), err)
+}
@@ -0,0 +1,589 @@
+package env
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/mail"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// converter parses a value into a reflect.Value
+type converter func(v string) (reflect.Value, error)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+	addressType  = reflect.TypeOf(mail.Address{})
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bigFloatPrec is the precision, in bits, of parsed big.Floats
+const bigFloatPrec = 256
+
+// typeConverters convert the types that need special handling regardless of
+// their kind. They are looked up first, merged with the custom parsers.
+var typeConverters = map[reflect.Type]converter{
+	durationType: func(v string) (reflect.Value, error) {
+		d, err := time.ParseDuration(v)
+		return reflect.ValueOf(d), err
+	},
+	fileModeType: parseFileMode,
+	addressType: func(v string) (reflect.Value, error) {
+		addr, err := mail.ParseAddress(v)
+		if err != nil {
+			return reflect.Value{}, errors.New("Invalid mail address " + v + ": " + err.Error())
+		}
+		return reflect.ValueOf(*addr), nil
+	},
+	// lists are parsed as a whole, as display names may contain commas
+	reflect.TypeOf([]*mail.Address(nil)): func(v string) (reflect.Value, error) {
+		list, err := mail.ParseAddressList(v)
+		if err != nil {
+			return reflect.Value{}, errors.New("Invalid mail address list " + v + ": " + err.Error())
+		}
+		return reflect.ValueOf(list), nil
+	},
+	reflect.TypeOf(json.RawMessage(nil)): func(v string) (reflect.Value, error) {
+		if !json.Valid([]byte(v)) {
+			return reflect.Value{}, errors.New("Invalid JSON " + v)
+		}
+		return reflect.ValueOf(json.RawMessage(v)), nil
+	},
+	// lists are read from a JSON array, as elements may contain commas
+	reflect.TypeOf([]json.RawMessage(nil)): func(v string) (reflect.Value, error) {
+		var list []json.RawMessage
+		if err := json.Unmarshal([]byte(v), &list); err != nil {
+			return reflect.Value{}, errors.New("Invalid JSON array " + v + ": " + err.Error())
+		}
+		return reflect.ValueOf(list), nil
+	},
+	// big numbers can't be copied by value, see math/big, so values are
+	// deep copies made with Set and pointers are returned as parsed
+	reflect.TypeOf(big.Int{}): func(v string) (reflect.Value, error) {
+		n, err := parseBigInt(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(new(big.Int).Set(n)).Elem(), nil
+	},
+	reflect.TypeOf((*big.Int)(nil)): func(v string) (reflect.Value, error) {
+		n, err := parseBigInt(v)
+		return reflect.ValueOf(n), err
+	},
+	reflect.TypeOf(big.Float{}): func(v string) (reflect.Value, error) {
+		f, err := parseBigFloat(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(new(big.Float).Set(f)).Elem(), nil
+	},
+	reflect.TypeOf((*big.Float)(nil)): func(v string) (reflect.Value, error) {
+		f, err := parseBigFloat(v)
+		return reflect.ValueOf(f), err
+	},
+	reflect.TypeOf(big.Rat{}): func(v string) (reflect.Value, error) {
+		r, err := parseBigRat(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(new(big.Rat).Set(r)).Elem(), nil
+	},
+	reflect.TypeOf((*big.Rat)(nil)): func(v string) (reflect.Value, error) {
+		r, err := parseBigRat(v)
+		return reflect.ValueOf(r), err
+	},
+}
+
+func parseBigInt(v string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(v, 10)
+	if !ok {
+		return nil, errors.New("Invalid integer " + v)
+	}
+	return n, nil
+}
+
+func parseBigFloat(v string) (*big.Float, error) {
+	f, _, err := big.ParseFloat(v, 10, bigFloatPrec, big.ToNearestEven)
+	if err != nil {
+		return nil, errors.New("Invalid number " + v + ": " + err.Error())
+	}
+	return f, nil
+}
+
+func parseBigRat(v string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, errors.New("Invalid number " + v)
+	}
+	return r, nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = CustomParsers{}
+)
+
+// RegisterParser registers a parser for type t used by every Parse call,
+// e.g. by packages adding support for arbitrary-precision decimal types.
+// Registered parsers take precedence over the built-in ones, and parsers
+// given to a Parse call take precedence over registered ones. Their errors
+// are prefixed by the variable, e.g. `BACKUP_CRON: Invalid cron expression`.
+func RegisterParser(t reflect.Type, f ParserFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = f
+}
+
+// kindConverters convert the basic kinds. Their results are converted to the
+// target type afterwards, so named types such as `type Level int` work too.
+var kindConverters = map[reflect.Kind]converter{
+	reflect.String: func(v string) (reflect.Value, error) {
+		return reflect.ValueOf(v), nil
+	},
+	reflect.Bool: func(v string) (reflect.Value, error) {
+		bvalue, err := strconv.ParseBool(v)
+		return reflect.ValueOf(bvalue), err
+	},
+	reflect.Int: func(v string) (reflect.Value, error) {
+		intValue, err := strconv.ParseInt(v, 10, 32)
+		return reflect.ValueOf(int(intValue)), err
+	},
+	reflect.Int64: func(v string) (reflect.Value, error) {
+		intValue, err := strconv.ParseInt(v, 10, 64)
+		return reflect.ValueOf(intValue), err
+	},
+	reflect.Uint: func(v string) (reflect.Value, error) {
+		uintValue, err := strconv.ParseUint(v, 10, 32)
+		return reflect.ValueOf(uint(uintValue)), err
+	},
+	reflect.Float32: func(v string) (reflect.Value, error) {
+		floatValue, err := strconv.ParseFloat(v, 32)
+		return reflect.ValueOf(float32(floatValue)), err
+	},
+	reflect.Float64: func(v string) (reflect.Value, error) {
+		floatValue, err := strconv.ParseFloat(v, 64)
+		return reflect.ValueOf(floatValue), err
+	},
+}
+
+// parseFileMode parses permission bits written in octal, such as 0644
+func parseFileMode(v string) (reflect.Value, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(v, "0o"), 8, 32)
+	if err != nil || mode > uint64(os.ModePerm) {
+		return reflect.Value{}, errors.New("Invalid file mode " + v + ", expected octal permissions from 0 to 0777")
+	}
+	return reflect.ValueOf(os.FileMode(mode)), nil
+}
+
+// parseRelaxedBool accepts yes/no, on/off and enabled/disabled, case
+// insensitively, in addition to what strconv.ParseBool accepts
+func parseRelaxedBool(v string) (reflect.Value, error) {
+	switch strings.ToLower(v) {
+	case "yes", "y", "on", "enabled", "enable":
+		return reflect.ValueOf(true), nil
+	case "no", "n", "off", "disabled", "disable":
+		return reflect.ValueOf(false), nil
+	}
+	bvalue, err := strconv.ParseBool(strings.ToLower(v))
+	return reflect.ValueOf(bvalue), err
+}
+
+// parseExtendedDuration parses durations like time.ParseDuration, also
+// accepting days (d) and weeks (w) of 24 and 168 hours, e.g. `1.5d` or `2w3d`
+func parseExtendedDuration(v string) (reflect.Value, error) {
+	invalid := errors.New("Invalid duration " + v)
+	s, neg := v, false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s, neg = s[1:], s[0] == '-'
+	}
+	if s == "" {
+		return reflect.Value{}, invalid
+	}
+	if s == "0" {
+		return reflect.ValueOf(time.Duration(0)), nil
+	}
+	isNumber := func(c byte) bool { return c == '.' || '0' <= c && c <= '9' }
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && isNumber(s[i]) {
+			i++
+		}
+		j := i
+		for j < len(s) && !isNumber(s[j]) {
+			j++
+		}
+		number, unit := s[:i], s[i:j]
+		s = s[j:]
+		var d time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(number, 64)
+			unitLen := 24 * time.Hour
+			if unit == "w" {
+				unitLen *= 7
+			}
+			if err != nil || f*float64(unitLen) >= math.MaxInt64 {
+				return reflect.Value{}, invalid
+			}
+			d = time.Duration(f * float64(unitLen))
+		default:
+			var err error
+			if d, err = time.ParseDuration(number + unit); err != nil {
+				return reflect.Value{}, invalid
+			}
+		}
+		if total += d; total < 0 {
+			return reflect.Value{}, invalid
+		}
+	}
+	if neg {
+		total = -total
+	}
+	return reflect.ValueOf(total), nil
+}
+
+// intConverter converts integers written in the given base. With base 0, the
+// base is implied by the prefix (0x, 0o, 0b or 0) and underscores are allowed.
+func intConverter(kind reflect.Kind, base int) converter {
+	return func(v string) (reflect.Value, error) {
+		switch kind {
+		case reflect.Int:
+			intValue, err := strconv.ParseInt(v, base, 32)
+			return reflect.ValueOf(int(intValue)), err
+		case reflect.Uint:
+			uintValue, err := strconv.ParseUint(v, base, 32)
+			return reflect.ValueOf(uint(uintValue)), err
+		default:
+			intValue, err := strconv.ParseInt(v, base, 64)
+			return reflect.ValueOf(intValue), err
+		}
+	}
+}
+
+// converters maps types to their converters, see typeConverters
+type converters map[reflect.Type]converter
+
+// newConverters merges the built-in type converters with the registered
+// parsers and funcMap, in increasing order of precedence
+func newConverters(funcMap CustomParsers) converters {
+	convs := make(converters, len(typeConverters)+len(funcMap))
+	for t, c := range typeConverters {
+		convs[t] = c
+	}
+	registryMu.RLock()
+	for t, f := range registry {
+		convs[t] = registeredConverter(f)
+	}
+	registryMu.RUnlock()
+	for t, f := range funcMap {
+		convs[t] = customConverter(f)
+	}
+	return convs
+}
+
+func customConverter(parserFunc ParserFunc) converter {
+	return func(v string) (reflect.Value, error) {
+		data, err := parserFunc(v)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Custom parser error: %v", err)
+		}
+		return reflect.ValueOf(data), nil
+	}
+}
+
+// registeredError is an error of a registered parser, prefixed by the
+// variable by convert
+type registeredError struct {
+	err error
+}
+
+func (e registeredError) Error() string {
+	return e.err.Error()
+}
+
+func registeredConverter(parserFunc ParserFunc) converter {
+	return func(v string) (reflect.Value, error) {
+		data, err := parserFunc(v)
+		if err != nil {
+			return reflect.Value{}, registeredError{err}
+		}
+		return reflect.ValueOf(data), nil
+	}
+}
+
+// conversion configures how convert parses the value of a field
+type conversion struct {
+	tagInfo
+	convs converters
+	// path of the field, for errors, empty if unknown
+	field string
+}
+
+func (o *options) conversion(info tagInfo) *conversion {
+	info.relaxedBool = info.relaxedBool || o.relaxed
+	info.extendedDuration = info.extendedDuration || o.extended
+	if info.base == -1 {
+		info.base = o.base
+	}
+	return &conversion{tagInfo: info, convs: o.converters, field: o.currentPath}
+}
+
+// checkParsed verifies that v, returned by the parser of t, is a value of t
+func (c *conversion) checkParsed(v reflect.Value, t reflect.Type) error {
+	if v.IsValid() && v.Type().AssignableTo(t) {
+		return nil
+	}
+	actual := "nil"
+	if v.IsValid() {
+		actual = v.Type().String()
+	}
+	if c.field == "" && c.key == "" {
+		return errors.New("Parser of " + t.String() + " returned " + actual)
+	}
+	field := c.field
+	if c.key != "" {
+		field += " (" + c.key + ")"
+	}
+	return errors.New("Parser of field " + field + " returned " + actual + ", expected " + t.String())
+}
+
+// Convert parses value into a value of type t, following the rules Parse
+// applies to the value of a field of that type: custom and registered
+// parsers, encoding.TextUnmarshaler, lists and maps separated by `,` and
+// `:`, and the options, such as WithRelaxedBool or WithExtendedDurations.
+// It lets other tools, such as flag binders, parse values exactly as Parse
+// does:
+//
+//	v, err := env.Convert("1s,1m", reflect.TypeOf([]time.Duration{}))
+func Convert(value string, t reflect.Type, opts ...Option) (reflect.Value, error) {
+	o := newOptions(context.Background(), opts)
+	if o.trim {
+		value = trim(value)
+	}
+	return convert(value, t, o.conversion(tagInfo{separator: ",", keyValSeparator: ":", base: -1}))
+}
+
+func set(field reflect.Value, value string, c *conversion) error {
+	v, err := convert(value, field.Type(), c)
+	if err != nil {
+		return err
+	}
+	field.Set(v)
+	return nil
+}
+
+// convertUnmarshaler is implemented by types made of values parsed like
+// fields, such as Range, so they are parsed with the conversion of their
+// field rather than by UnmarshalText
+type convertUnmarshaler interface {
+	unmarshalWith(value string, c *conversion) error
+}
+
+var convertUnmarshalerType = reflect.TypeOf((*convertUnmarshaler)(nil)).Elem()
+
+// defaultConversion parses values as a field without options, for the
+// UnmarshalText methods of convertUnmarshalers
+func defaultConversion() *conversion {
+	return &conversion{tagInfo: tagInfo{base: 10, separator: ","}, convs: newConverters(nil)}
+}
+
+// convert parses value into a new value of type t, looking up a converter
+// by type, then using encoding.TextUnmarshaler if t implements it, then by
+// kind
+func convert(value string, t reflect.Type, c *conversion) (reflect.Value, error) {
+	if t == durationType && c.extendedDuration {
+		return parseExtendedDuration(value)
+	}
+	if t == moneyType {
+		m, err := parseMoney(value, c.currency, c.minorUnits)
+		return reflect.ValueOf(m), err
+	}
+	if conv, ok := c.convs[t]; ok {
+		v, err := conv(value)
+		if r, ok := err.(registeredError); ok {
+			err = r.err
+			if c.key != "" {
+				err = errors.New(c.key + ": " + err.Error())
+			}
+		}
+		if err == nil {
+			err = c.checkParsed(v, t)
+		}
+		return v, err
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(convertUnmarshalerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(convertUnmarshaler).unmarshalWith(value, c); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		v, err := convert(value, t.Elem(), c)
+		if err != nil {
+			return v, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	case reflect.Slice:
+		return handleSlice(value, t, c)
+	case reflect.Map:
+		return handleMap(value, t, c)
+	case reflect.Bool:
+		if c.relaxedBool {
+			return convertKind(value, t, parseRelaxedBool)
+		}
+	case reflect.Int, reflect.Int64, reflect.Uint:
+		if c.base != 10 {
+			return convertKind(value, t, intConverter(t.Kind(), c.base))
+		}
+	}
+
+	conv, ok := kindConverters[t.Kind()]
+	if !ok {
+		return reflect.Value{}, ErrUnsupportedType
+	}
+	return convertKind(value, t, conv)
+}
+
+// convertKind converts value with conv, a kind converter, into type t
+func convertKind(value string, t reflect.Type, conv converter) (reflect.Value, error) {
+	v, err := conv(value)
+	if err != nil {
+		return v, err
+	}
+	return v.Convert(t), nil
+}
+
+func handleSlice(value string, t reflect.Type, c *conversion) (reflect.Value, error) {
+	if !isElemSupported(t.Elem(), c.convs) {
+		return reflect.Value{}, ErrUnsupportedSliceType
+	}
+
+	splitData, err := c.splitItems(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	slice := reflect.MakeSlice(t, 0, len(splitData))
+	for _, item := range splitData {
+		v, err := convert(item, t.Elem(), c)
+		if err == ErrUnsupportedType {
+			return slice, ErrUnsupportedSliceType
+		}
+		if err != nil {
+			return slice, err
+		}
+		slice = reflect.Append(slice, v)
+	}
+	return slice, nil
+}
+
+func handleMap(value string, t reflect.Type, c *conversion) (reflect.Value, error) {
+	if isNestedMap(t, c.convs) {
+		return handleNestedMap(value, t, c)
+	}
+	if !isElemSupported(t.Key(), c.convs) || !isElemSupported(t.Elem(), c.convs) {
+		return reflect.Value{}, ErrUnsupportedType
+	}
+	if isSet(t) {
+		return handleSet(value, t, c)
+	}
+
+	pairs, err := c.splitPairs(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	m := reflect.MakeMap(t)
+	for _, pair := range pairs {
+		k, err := convert(pair[0], t.Key(), c)
+		if err != nil {
+			return m, err
+		}
+		v, err := convert(pair[1], t.Elem(), c)
+		if err != nil {
+			return m, err
+		}
+		m.SetMapIndex(k, v)
+	}
+	return m, nil
+}
+
+// handleNestedMap converts maps of maps, e.g. `a=k1:v1;k2:v2,b=k3:v3`. The
+// outer map is split like other maps, except for the separator of its keys
+// and values, `envOuterKeyValSeparator`; the nested maps are split on
+// `envInnerSeparator` and `envKeyValSeparator`.
+func handleNestedMap(value string, t reflect.Type, c *conversion) (reflect.Value, error) {
+	outer, inner := *c, *c
+	outer.keyValSeparator = c.outerKeyValSeparator
+	if outer.keyValSeparator == "" {
+		outer.keyValSeparator = "="
+	}
+	inner.separator = c.innerSeparator
+	if inner.separator == "" {
+		inner.separator = ";"
+	}
+	pairs, err := outer.splitPairs(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	m := reflect.MakeMap(t)
+	for _, pair := range pairs {
+		k, err := convert(pair[0], t.Key(), c)
+		if err != nil {
+			return m, err
+		}
+		v, err := handleMap(pair[1], t.Elem(), &inner)
+		if err != nil {
+			return m, err
+		}
+		m.SetMapIndex(k, v)
+	}
+	return m, nil
+}
+
+// isNestedMap reports whether t is a map of maps, whose keys and values
+// are supported elements
+func isNestedMap(t reflect.Type, convs converters) bool {
+	if t.Kind() != reflect.Map || t.Elem().Kind() != reflect.Map || !isElemSupported(t.Key(), convs) {
+		return false
+	}
+	if _, ok := convs[t.Elem()]; ok || reflect.PtrTo(t.Elem()).Implements(textUnmarshalerType) {
+		return false
+	}
+	return isElemSupported(t.Elem().Key(), convs) && isElemSupported(t.Elem().Elem(), convs)
+}
+
+// isElemSupported reports whether t can be the element of a slice or map.
+// Slices and maps can't be nested as they would share separators, except
+// for maps of maps, see handleNestedMap.
+func isElemSupported(t reflect.Type, convs converters) bool {
+	if _, ok := convs[t]; ok {
+		return true
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() != reflect.Slice && t.Kind() != reflect.Map
+}
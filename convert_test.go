@@ -0,0 +1,53 @@
+package env_test
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	v, err := env.Convert("1s,1m", reflect.TypeOf([]time.Duration{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, v.Interface())
+
+	v, err = env.Convert("read:10,write:5", reflect.TypeOf(map[string]int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"read": 10, "write": 5}, v.Interface())
+
+	v, err = env.Convert("10.0.0.1", reflect.TypeOf(net.IP{}))
+	assert.NoError(t, err)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), v.Interface())
+
+	v, err = env.Convert(" yes ", reflect.TypeOf(true), env.WithRelaxedBool(), env.WithTrim())
+	assert.NoError(t, err)
+	assert.Equal(t, true, v.Interface())
+
+	v, err = env.Convert("2w", reflect.TypeOf(time.Duration(0)), env.WithExtendedDurations())
+	assert.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, v.Interface())
+
+	type celsius float64
+	v, err = env.Convert("21.5C", reflect.TypeOf(celsius(0)), env.WithFuncs(env.CustomParsers{
+		reflect.TypeOf(celsius(0)): func(v string) (interface{}, error) {
+			return celsius(21.5), nil
+		},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, celsius(21.5), v.Interface())
+
+	_, err = env.Convert("x", reflect.TypeOf(celsius(0)), env.WithFuncs(env.CustomParsers{
+		reflect.TypeOf(celsius(0)): func(v string) (interface{}, error) {
+			return 21.5, nil
+		},
+	}))
+	assert.Equal(t, errors.New("Parser of env_test.celsius returned float64"), err)
+
+	_, err = env.Convert("x", reflect.TypeOf(0))
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "x": invalid syntax`)
+}
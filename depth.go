@@ -0,0 +1,47 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxDepth is how deep structs may be nested by default
+const defaultMaxDepth = 32
+
+// WithMaxDepth sets how deep structs may be nested, 32 by default, so that
+// self-referential config types fail with a clear error rather than
+// exhausting the stack
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// enter checks that the struct nested in the field at fieldPath, v or the
+// struct v points to, can be parsed: it mustn't be nested too deep nor be
+// one of the structs it is nested in. leave must be called once it is parsed.
+func (o *options) enter(v reflect.Value, fieldPath string) error {
+	if depth := strings.Count(fieldPath, ".") + 1; depth > o.maxDepth {
+		return errors.New("Field " + fieldPath + " is nested deeper than the maximum depth of " + strconv.Itoa(o.maxDepth))
+	}
+	if v.Kind() != reflect.Ptr {
+		return nil
+	}
+	if other, ok := o.visiting[v.Pointer()]; ok {
+		if other == "" {
+			other = "the parsed struct"
+		}
+		return errors.New("Field " + fieldPath + " points back to " + other + ", which is a cycle")
+	}
+	o.visiting[v.Pointer()] = fieldPath
+	return nil
+}
+
+// leave marks the struct nested in a field as parsed, see enter
+func (o *options) leave(v reflect.Value) {
+	if v.Kind() == reflect.Ptr {
+		delete(o.visiting, v.Pointer())
+	}
+}
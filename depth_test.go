@@ -0,0 +1,41 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type node struct {
+	Name string `env:"NAME"`
+	Next *node  `envPrefix:"NEXT_"`
+}
+
+func TestCycle(t *testing.T) {
+	n := &node{}
+	n.Next = &node{Next: n}
+	err := env.Parse(n, env.WithParallelLookups(2), env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Field Next.Next points back to the parsed struct, which is a cycle"), err)
+
+	_, err = env.DryRun(n, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Field Next.Next points back to the parsed struct, which is a cycle"), err)
+
+	type pair struct {
+		A *node
+		B *node
+	}
+	shared := &node{}
+	assert.NoError(t, env.Parse(&pair{A: shared, B: shared}, env.WithLookuper(env.Map{"NAME": "shared"})))
+	assert.Equal(t, "shared", shared.Name)
+}
+
+func TestMaxDepth(t *testing.T) {
+	n := &node{Next: &node{Next: &node{}}}
+	assert.NoError(t, env.Parse(n, env.WithLookuper(env.Map{"NEXT_NEXT_NAME": "c"})))
+	assert.Equal(t, "c", n.Next.Next.Name)
+
+	err := env.Parse(n, env.WithMaxDepth(1), env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Field Next.Next is nested deeper than the maximum depth of 1"), err)
+}
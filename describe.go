@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Var describes an environment variable loaded into a struct field. A list
+// of Vars, as returned by Describe, can be stored as JSON and later used as a
+// spec to Check an environment without the struct at hand.
+type Var struct {
+	// Field is the dotted path of the struct field, e.g. "Database.Host"
+	Field string `json:"field"`
+	// Key is the name of the environment variable
+	Key string `json:"key"`
+	// Type is the Go type of the field, e.g. "int" or "[]string"
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	Required  bool   `json:"required,omitempty"`
+	Secret    bool   `json:"secret,omitempty"`
+	Separator string `json:"separator,omitempty"`
+	// OneOf lists the allowed values, if restricted with `envOneOf`
+	OneOf []string `json:"oneOf,omitempty"`
+	// Description documents the variable. Describe leaves it empty; envdoc
+	// fills it from the field's doc comment.
+	Description string `json:"description,omitempty"`
+}
+
+// Describe lists the environment variables read by Parse for the given
+// struct (or pointer to struct), in field declaration order
+func Describe(v interface{}) ([]Var, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+	return describe(t, "", "", map[reflect.Type]bool{}, nil)
+}
+
+func describe(t reflect.Type, path, prefix string, seen map[reflect.Type]bool, vars []Var) ([]Var, error) {
+	seen[t] = true
+	defer delete(seen, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		info, err := parseTag(field)
+		if err != nil {
+			return nil, err
+		}
+		if info.key == "" {
+			nested := field.Type
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+			if isStruct(nested, nil) && !seen[nested] {
+				if vars, err = describe(nested, path+field.Name+".", prefix+info.prefix, seen, vars); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		vars = append(vars, Var{
+			Field:     path + field.Name,
+			Key:       prefix + info.key,
+			Type:      field.Type.String(),
+			Default:   info.defaultValue,
+			Required:  info.required,
+			Secret:    info.secret,
+			Separator: field.Tag.Get("envSeparator"),
+			OneOf:     info.oneOf,
+		})
+	}
+	return vars, nil
+}
+
+// builtinTypes maps the type names used in Var to the types Parse supports
+// out of the box
+var builtinTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, t := range []reflect.Type{
+		reflect.TypeOf(""),
+		reflect.TypeOf(0),
+		reflect.TypeOf(uint(0)),
+		reflect.TypeOf(int64(0)),
+		reflect.TypeOf(false),
+		reflect.TypeOf(float32(0)),
+		reflect.TypeOf(float64(0)),
+		durationType,
+		fileModeType,
+		reflect.TypeOf(Percent(0)),
+		reflect.TypeOf([]string(nil)),
+		reflect.TypeOf([]int(nil)),
+		reflect.TypeOf([]int64(nil)),
+		reflect.TypeOf([]bool(nil)),
+		reflect.TypeOf([]float32(nil)),
+		reflect.TypeOf([]float64(nil)),
+	} {
+		builtinTypes[t.String()] = t
+	}
+}
+
+// Check validates the values l holds for the given vars, reporting missing
+// required variables and values that cannot be parsed into their type.
+// Values of types not supported out of the box are not validated.
+func Check(vars []Var, l Lookuper) []error {
+	var errs []error
+	for _, v := range vars {
+		value, ok := l.Lookup(v.Key)
+		if !ok {
+			if v.Required {
+				errs = append(errs, errors.New("Required environment variable "+v.Key+" is not set"))
+			}
+			continue
+		}
+		info := tagInfo{key: v.Key, separator: v.Separator, oneOf: v.OneOf}
+		if info.separator == "" {
+			info.separator = ","
+		}
+		shape := reflect.TypeOf("")
+		if strings.HasPrefix(v.Type, "[]") {
+			shape = reflect.TypeOf([]string(nil))
+		}
+		if err := info.checkOneOf(value, shape); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t, supported := builtinTypes[v.Type]
+		if !supported || value == "" {
+			continue
+		}
+		c := &conversion{
+			tagInfo: tagInfo{separator: v.Separator, keyValSeparator: ":", base: 10},
+			convs:   typeConverters,
+		}
+		if c.separator == "" {
+			c.separator = ","
+		}
+		if err := set(reflect.New(t).Elem(), value, c); err != nil {
+			errs = append(errs, errors.New("Invalid value for "+v.Key+": "+err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,95 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	type config struct {
+		Home     string   `env:"HOME"`
+		Port     int      `env:"PORT" envDefault:"3000"`
+		Hosts    []string `env:"HOSTS" envSeparator:":"`
+		Password string   `env:"PASSWORD,required,secret"`
+		Inner    *InnerStruct
+		NotAnEnv string
+	}
+	vars, err := env.Describe(&config{})
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Var{
+		{Field: "Home", Key: "HOME", Type: "string"},
+		{Field: "Port", Key: "PORT", Type: "int", Default: "3000"},
+		{Field: "Hosts", Key: "HOSTS", Type: "[]string", Separator: ":"},
+		{Field: "Password", Key: "PASSWORD", Type: "string", Required: true, Secret: true},
+		{Field: "Inner.Inner", Key: "innervar", Type: "string"},
+	}, vars)
+}
+
+func TestDescribeSelfReferencing(t *testing.T) {
+	type node struct {
+		Name string `env:"NAME"`
+		Next *node
+	}
+	vars, err := env.Describe(node{})
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Var{{Field: "Name", Key: "NAME", Type: "string"}}, vars)
+}
+
+func TestDescribePrefix(t *testing.T) {
+	type config struct {
+		Primary *upstream `envPrefix:"DB_"`
+	}
+	vars, err := env.Describe(config{})
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Var{
+		{Field: "Primary.Host", Key: "DB_HOST", Type: "string", Required: true},
+		{Field: "Primary.Port", Key: "DB_PORT", Type: "int", Default: "80"},
+	}, vars)
+}
+
+func TestDescribeNotAStruct(t *testing.T) {
+	_, err := env.Describe(1)
+	assert.Equal(t, env.ErrNotAStructPtr, err)
+	_, err = env.Describe(nil)
+	assert.Equal(t, env.ErrNotAStructPtr, err)
+}
+
+func TestCheck(t *testing.T) {
+	vars := []env.Var{
+		{Key: "PORT", Type: "int"},
+		{Key: "HOSTS", Type: "[]int", Separator: ":"},
+		{Key: "TIMEOUT", Type: "time.Duration"},
+		{Key: "SECRET", Type: "string", Required: true},
+		{Key: "URL", Type: "url.URL"},
+		{Key: "LEVEL", Type: "string", OneOf: []string{"debug", "info"}},
+	}
+	errs := env.Check(vars, env.Map{
+		"PORT":    "not-a-number",
+		"HOSTS":   "1:2:3",
+		"TIMEOUT": "1s",
+		"URL":     "whatever",
+		"LEVEL":   "trace",
+	})
+	assert.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], `Invalid value for PORT: strconv.ParseInt: parsing "not-a-number": invalid syntax`)
+	assert.EqualError(t, errs[1], "Required environment variable SECRET is not set")
+	assert.EqualError(t, errs[2], "Invalid value for LEVEL, expected one of debug, info")
+}
+
+func TestCheckAgreesWithParse(t *testing.T) {
+	type config struct {
+		Port  int   `env:"PORT"`
+		Sizes []int `env:"SIZES"`
+	}
+	vars, err := env.Describe(config{})
+	assert.NoError(t, err)
+	for _, value := range []string{"0x50", "1_000", "0o17", "017"} {
+		environ := env.Map{"PORT": value, "SIZES": "1," + value}
+		parseErr := env.Parse(&config{}, env.WithLookuper(environ))
+		checkErrs := env.Check(vars, environ)
+		assert.Equal(t, parseErr == nil, len(checkErrs) == 0, value)
+	}
+	assert.Len(t, env.Check(vars, env.Map{"PORT": "0x50"}), 1)
+}
@@ -0,0 +1,131 @@
+package env
+
+import (
+	"context"
+	"reflect"
+)
+
+// redacted replaces the value of fields tagged with the `secret` option
+// whenever a value is displayed or compared outside of the struct.
+const redacted = "[REDACTED]"
+
+// FieldChange describes a field whose value differs between two configs
+type FieldChange struct {
+	// Field is the dotted path of the struct field, e.g. "Database.Host"
+	Field string
+	// Key is the environment variable the field is loaded from
+	Key string
+	// Old and New hold the values of the field in each config. Fields tagged
+	// with the `secret` option have both values replaced by a redacted marker.
+	Old interface{}
+	New interface{}
+	// Forbidden is true if the field is tagged `envReload:"forbid"`, i.e. it
+	// can't change without a restart
+	Forbidden bool
+}
+
+// Diff compares two configs of the same struct type field-by-field and
+// returns the fields loaded from environment variables whose values differ.
+// Both structs and pointers to structs are accepted; Diff returns nil if
+// old and new are not of the same struct type. Refreshing fields are
+// compared by their current values, and Lazy fields, resolved when read,
+// are left out. opts are the options of the parse, such as WithPrefix,
+// which decide the keys of the fields.
+func Diff(old, new interface{}, opts ...Option) []FieldChange {
+	oldRef := indirect(reflect.ValueOf(old))
+	newRef := indirect(reflect.ValueOf(new))
+	if oldRef.Kind() != reflect.Struct || newRef.Kind() != reflect.Struct {
+		return nil
+	}
+	if oldRef.Type() != newRef.Type() {
+		return nil
+	}
+	o := newOptions(context.Background(), opts)
+	return o.diffStruct(oldRef, newRef, "", o.prefix, nil)
+}
+
+func (o *options) diffStruct(oldRef, newRef reflect.Value, path, prefix string, changes []FieldChange) []FieldChange {
+	refType := oldRef.Type()
+	for i := 0; i < refType.NumField(); i++ {
+		field := refType.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		oldField, newField := oldRef.Field(i), newRef.Field(i)
+		// invalid tags are reported by Parse
+		info, _ := o.prefixedInfo(field, path+field.Name, prefix)
+		if info.key == "" {
+			if isStructOrStructPtr(field.Type) && !bothNil(oldField, newField) {
+				changes = o.diffStruct(indirect(oldField), indirect(newField), path+field.Name+".", o.nestedPrefix(prefix, field.Name, info), changes)
+			}
+			continue
+		}
+		if reflect.PtrTo(field.Type).Implements(lazyFieldType) {
+			// resolved when read, so there is no value to compare
+			continue
+		}
+		oldValue, newValue := compared(oldField), compared(newField)
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := FieldChange{
+			Field:     path + field.Name,
+			Key:       info.key,
+			Old:       oldValue,
+			New:       newValue,
+			Forbidden: info.reloadForbidden,
+		}
+		if info.secret {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// compared returns the value of v compared by Diff: the current value of
+// Refreshing fields, read under their lock, rather than their internals
+func compared(v reflect.Value) interface{} {
+	if !reflect.PtrTo(v.Type()).Implements(currentValuerType) {
+		return v.Interface()
+	}
+	if !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	return v.Addr().Interface().(currentValuer).current()
+}
+
+// indirect dereferences pointers, returning the zero value of the pointed
+// type for nil pointers so they compare as empty structs.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isStructOrStructPtr(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func bothNil(a, b reflect.Value) bool {
+	return a.Kind() == reflect.Ptr && a.IsNil() && b.IsNil()
+}
+
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type diffConfig struct {
+	Host     string `env:"HOST"`
+	Port     int    `env:"PORT" envReload:"forbid"`
+	Password string `env:"PASSWORD,secret"`
+	Inner    *InnerStruct
+	NotAnEnv string
+}
+
+func TestDiff(t *testing.T) {
+	old := diffConfig{
+		Host:     "localhost",
+		Port:     8080,
+		Password: "old",
+		Inner:    &InnerStruct{Inner: "a"},
+		NotAnEnv: "a",
+	}
+	new := diffConfig{
+		Host:     "localhost",
+		Port:     9090,
+		Password: "new",
+		Inner:    &InnerStruct{Inner: "b"},
+		NotAnEnv: "b",
+	}
+	assert.Equal(t, []env.FieldChange{
+		{Field: "Port", Key: "PORT", Old: 8080, New: 9090, Forbidden: true},
+		{Field: "Password", Key: "PASSWORD", Old: "[REDACTED]", New: "[REDACTED]"},
+		{Field: "Inner.Inner", Key: "innervar", Old: "a", New: "b"},
+	}, env.Diff(&old, &new))
+}
+
+func TestDiffPrefixed(t *testing.T) {
+	type database struct {
+		Host string `env:"HOST"`
+	}
+	type config struct {
+		Database database `envPrefix:"DB_"`
+		Replica  *database
+	}
+	old := config{Database: database{Host: "a"}, Replica: &database{Host: "a"}}
+	new := config{Database: database{Host: "b"}, Replica: &database{Host: "b"}}
+	assert.Equal(t, []env.FieldChange{
+		{Field: "Database.Host", Key: "APP_DB_HOST", Old: "a", New: "b"},
+		{Field: "Replica.Host", Key: "APP_REPLICA_HOST", Old: "a", New: "b"},
+	}, env.Diff(old, new, env.WithPrefix("APP_"), env.WithDerivedPrefixes()))
+}
+
+func TestDiffNilInner(t *testing.T) {
+	old := diffConfig{}
+	new := diffConfig{Inner: &InnerStruct{Inner: "b"}}
+	assert.Equal(t, []env.FieldChange{
+		{Field: "Inner.Inner", Key: "innervar", Old: "", New: "b"},
+	}, env.Diff(old, new))
+}
+
+func TestDiffEqual(t *testing.T) {
+	cfg := diffConfig{Host: "localhost"}
+	assert.Empty(t, env.Diff(cfg, cfg))
+}
+
+func TestDiffDifferentTypes(t *testing.T) {
+	assert.Nil(t, env.Diff(diffConfig{}, Config{}))
+	assert.Nil(t, env.Diff(1, 2))
+}
+
+func TestDiffLazy(t *testing.T) {
+	type config struct {
+		Token env.Lazy[string] `env:"TOKEN" envReload:"forbid"`
+		Host  string           `env:"HOST"`
+	}
+	var old, new config
+	assert.NoError(t, env.Parse(&old, env.WithLookuper(env.Map{"TOKEN": "a", "HOST": "localhost"})))
+	assert.NoError(t, env.Parse(&new, env.WithLookuper(env.Map{"TOKEN": "b", "HOST": "localhost"})))
+	assert.Empty(t, env.Diff(&old, &new))
+
+	h, err := env.NewHolder(func() interface{} { return &config{} }, env.WithLookuper(env.Map{"TOKEN": "a"}))
+	assert.NoError(t, err)
+	changes, err := h.Reload()
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiffRefreshing(t *testing.T) {
+	type config struct {
+		Region env.Refreshing[string] `env:"REGION"`
+	}
+	var old, same, new config
+	assert.NoError(t, env.Parse(&old, env.WithLookuper(env.Map{"REGION": "eu"})))
+	assert.NoError(t, env.Parse(&same, env.WithLookuper(env.Map{"REGION": "eu"})))
+	assert.NoError(t, env.Parse(&new, env.WithLookuper(env.Map{"REGION": "us"})))
+	assert.Empty(t, env.Diff(&old, &same))
+	assert.Equal(t, []env.FieldChange{
+		{Field: "Region", Key: "REGION", Old: "eu", New: "us"},
+	}, env.Diff(&old, &new))
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// idPlaceholder marks the id in the patterns of ParseInto
+const idPlaceholder = "<id>"
+
+// ParseInto parses one struct per id found in the keys matching pattern,
+// such as `TENANT_<id>_`, into m, a pointer to a map of structs, or of
+// pointers to structs, keyed by id. With `TENANT_ACME_DB_HOST` and
+// `TENANT_GLOBEX_DB_HOST` set, m gets the keys ACME and GLOBEX, parsed with
+// the prefixes `TENANT_ACME_` and `TENANT_GLOBEX_`. Ids are found by listing
+// the keys, so the Lookuper must implement KeyLister, as the process
+// environment does. The pattern must not end with the placeholder, so that
+// ids can be told from the keys of the struct. A prefix set with WithPrefix
+// is prepended to the pattern.
+func ParseInto(m interface{}, pattern string, opts ...Option) error {
+	ptrRef := reflect.ValueOf(m)
+	if ptrRef.Kind() != reflect.Ptr || ptrRef.Elem().Kind() != reflect.Map {
+		return errors.New("Expected a pointer to a map of structs")
+	}
+	t := ptrRef.Elem().Type()
+	o := newOptions(context.Background(), opts)
+	if !isStruct(t.Elem(), o.converters) {
+		return errors.New("Expected a pointer to a map of structs")
+	}
+	i := strings.Index(pattern, idPlaceholder)
+	if i < 0 {
+		return errors.New("Pattern " + pattern + " has no " + idPlaceholder + " placeholder")
+	}
+	before, after := o.prefix+pattern[:i], pattern[i+len(idPlaceholder):]
+	if after == "" {
+		return errors.New("Pattern " + pattern + " has nothing after " + idPlaceholder + " to tell ids from keys")
+	}
+	ids, err := discoverIDs(before, after, o)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeMap(t)
+	var errs []error
+	for _, id := range ids {
+		k, err := convert(id, t.Key(), o.conversion(tagInfo{base: -1}))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		elem, ref := newStruct(t.Elem())
+		if err := Parse(ref.Addr().Interface(), append(opts, WithPrefix(before+id+after))...); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result.SetMapIndex(k, elem)
+	}
+	ptrRef.Elem().Set(result)
+	return joinErrors(errs...)
+}
+
+// discoverIDs returns the distinct ids between before and after in the keys
+// of the Lookuper, sorted
+func discoverIDs(before, after string, o *options) ([]string, error) {
+	lister, ok := o.lookuper.(KeyLister)
+	if !ok {
+		return nil, errors.New("Pattern " + before + idPlaceholder + after + " requires a Lookuper that lists its keys")
+	}
+	seen := map[string]bool{}
+	var ids []string
+	for _, key := range lister.Keys() {
+		if !strings.HasPrefix(key, before) {
+			continue
+		}
+		rest := key[len(before):]
+		end := strings.Index(rest, after)
+		if end <= 0 {
+			continue
+		}
+		if id := rest[:end]; !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
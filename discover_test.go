@@ -0,0 +1,58 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenant struct {
+	DBHost string `env:"DB_HOST,required"`
+	Quota  int    `env:"QUOTA" envDefault:"10"`
+}
+
+func TestParseInto(t *testing.T) {
+	l := env.WithLookuper(env.Map{
+		"TENANT_ACME_DB_HOST":   "acme.db",
+		"TENANT_ACME_QUOTA":     "50",
+		"TENANT_GLOBEX_DB_HOST": "globex.db",
+		"TENANT_GLOBEX":         "ignored",
+		"OTHER_DB_HOST":         "other.db",
+	})
+	tenants := map[string]tenant{}
+	assert.NoError(t, env.ParseInto(&tenants, "TENANT_<id>_", l))
+	assert.Equal(t, map[string]tenant{
+		"ACME":   {DBHost: "acme.db", Quota: 50},
+		"GLOBEX": {DBHost: "globex.db", Quota: 10},
+	}, tenants)
+
+	var pointers map[string]*tenant
+	assert.NoError(t, env.ParseInto(&pointers, "TENANT_<id>_", l))
+	assert.Equal(t, &tenant{DBHost: "globex.db", Quota: 10}, pointers["GLOBEX"])
+}
+
+func TestParseIntoWithPrefix(t *testing.T) {
+	l := env.WithLookuper(env.Map{
+		"APP_TENANT_ACME_DB_HOST": "acme.db",
+		"TENANT_GLOBEX_DB_HOST":   "globex.db",
+	})
+	tenants := map[string]tenant{}
+	assert.NoError(t, env.ParseInto(&tenants, "TENANT_<id>_", l, env.WithPrefix("APP_")))
+	assert.Equal(t, map[string]tenant{"ACME": {DBHost: "acme.db", Quota: 10}}, tenants)
+}
+
+func TestParseIntoErrors(t *testing.T) {
+	tenants := map[string]tenant{}
+	l := env.WithLookuper(env.Map{"TENANT_ACME_QUOTA": "50", "TENANT_GLOBEX_QUOTA": "x", "TENANT_INITECH_DB_HOST": "initech.db"})
+	err := env.ParseInto(&tenants, "TENANT_<id>_", l)
+	assert.EqualError(t, err, `Required environment variable TENANT_ACME_DB_HOST is not set. Required environment variable TENANT_GLOBEX_DB_HOST is not set. strconv.ParseInt: parsing "x": invalid syntax`)
+	assert.Equal(t, map[string]tenant{"INITECH": {DBHost: "initech.db", Quota: 10}}, tenants)
+
+	assert.Equal(t, errors.New("Pattern TENANT_ has no <id> placeholder"), env.ParseInto(&tenants, "TENANT_", l))
+	assert.Equal(t, errors.New("Pattern TENANT_<id> has nothing after <id> to tell ids from keys"), env.ParseInto(&tenants, "TENANT_<id>", l))
+	assert.Equal(t, errors.New("Expected a pointer to a map of structs"), env.ParseInto(tenants, "TENANT_<id>_", l))
+	assert.Equal(t, errors.New("Pattern TENANT_<id>_ requires a Lookuper that lists its keys"),
+		env.ParseInto(&tenants, "TENANT_<id>_", env.WithLookuper(env.LookuperFunc(func(string) (string, bool) { return "", false }))))
+}
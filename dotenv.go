@@ -0,0 +1,506 @@
+package env
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadDotEnv reads a .env file into a Map, which can be used with
+// `WithLookuper`, see ParseDotEnv for its syntax
+func LoadDotEnv(path string) (Map, error) {
+	d, err := OpenDotEnv(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.Vars, nil
+}
+
+// ReadDotEnv reads the content of a .env file from r into a Map, like
+// LoadDotEnv. name identifies r in errors, e.g. the name of a file.
+func ReadDotEnv(r io.Reader, name string) (Map, error) {
+	d, err := ParseDotEnv(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return d.Vars, nil
+}
+
+// LoadEnvrc reads the variables of a direnv `.envrc` file into a Map. The
+// file is read as a .env file, see ParseDotEnv, except that lines that
+// aren't assignments, such as `PATH_add bin`, `dotenv` or `layout go`, are
+// ignored, as are the commands of `$(...)` substitutions, which are kept as
+// is.
+func LoadEnvrc(path string) (Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := parseDotEnv(f, path, true)
+	if err != nil {
+		return nil, err
+	}
+	return d.Vars, nil
+}
+
+// DotEnv holds the variables of a .env file. It is a Lookuper that knows the
+// line each value comes from, which is mentioned in parse errors.
+type DotEnv struct {
+	// Name identifies the file, e.g. its path
+	Name string
+	Vars Map
+	// lines maps the keys to the line of their last definition
+	lines map[string]int
+	// spans maps the keys to the bytes of their last definition, from the
+	// key to the end of the value
+	spans map[string]span
+}
+
+// span is a range of bytes of a .env file
+type span struct {
+	start, end int
+}
+
+// OpenDotEnv reads the .env file at path, see ParseDotEnv
+func OpenDotEnv(path string) (*DotEnv, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseDotEnv(f, path)
+}
+
+// ParseDotEnv reads the content of a .env file from r. name identifies r in
+// errors, which give the line and column of syntax errors, e.g.
+// `.env:3:7: unterminated double-quoted value`.
+//
+// Each line is a `KEY=value` assignment, optionally preceded by `export`.
+// Blank lines and comments, from `#` to the end of the line, are ignored.
+// Unquoted values are trimmed. Single-quoted values are taken literally.
+// Double-quoted values may span several lines and contain the `\n`, `\r`,
+// `\t`, `\"`, `\\` and `\$` escapes. Unquoted and double-quoted values
+// may refer to variables defined earlier in the file, or in the process
+// environment, as `$KEY`, `${KEY}` or `${KEY:-default}`.
+func ParseDotEnv(r io.Reader, name string) (*DotEnv, error) {
+	return parseDotEnv(r, name, false)
+}
+
+// parseDotEnv reads a .env file, or a .envrc file whose directives are
+// ignored
+func parseDotEnv(r io.Reader, name string, envrc bool) (*DotEnv, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotEnvParser{
+		src:   string(src),
+		line:  1,
+		col:   1,
+		envrc: envrc,
+		d:     &DotEnv{Name: name, Vars: Map{}, lines: map[string]int{}, spans: map[string]span{}},
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.d, nil
+}
+
+// Lookup returns the value of key, if defined in the file
+func (d *DotEnv) Lookup(key string) (string, bool) {
+	return d.Vars.Lookup(key)
+}
+
+// Keys returns the keys defined in the file, sorted
+func (d *DotEnv) Keys() []string {
+	return d.Vars.Keys()
+}
+
+// Provenance returns the file and line defining key
+func (d *DotEnv) Provenance(key string) string {
+	line, ok := d.lines[key]
+	if !ok {
+		return ""
+	}
+	return "value from .env file " + d.Name + " line " + strconv.Itoa(line)
+}
+
+// Line returns the line of the last definition of key in the file, or 0
+func (d *DotEnv) Line(key string) int {
+	return d.lines[key]
+}
+
+// dotEnvParser tokenizes the content of a .env file
+type dotEnvParser struct {
+	src       string
+	pos       int
+	line, col int
+	d         *DotEnv
+	// whether lines other than assignments are ignored
+	envrc bool
+}
+
+// syntaxError is an error at the line and column given
+type syntaxError struct {
+	name      string
+	line, col int
+	msg       string
+}
+
+func (e *syntaxError) Error() string {
+	return e.name + ":" + strconv.Itoa(e.line) + ":" + strconv.Itoa(e.col) + ": " + e.msg
+}
+
+func (p *dotEnvParser) errorAt(line, col int, msg string) error {
+	return &syntaxError{name: p.d.Name, line: line, col: col, msg: msg}
+}
+
+// peek returns the next rune, or -1 at the end of the input
+func (p *dotEnvParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+// next consumes the next rune
+func (p *dotEnvParser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	if r == '\n' {
+		p.line, p.col = p.line+1, 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+func (p *dotEnvParser) skipSpaces() {
+	for r := p.peek(); r == ' ' || r == '\t' || r == '\r'; r = p.peek() {
+		p.next()
+	}
+}
+
+// skipComment consumes a comment up to, but excluding, the end of line
+func (p *dotEnvParser) skipComment() {
+	for r := p.peek(); r != '\n' && r != -1; r = p.peek() {
+		p.next()
+	}
+}
+
+func (p *dotEnvParser) parse() error {
+	for {
+		p.skipSpaces()
+		switch p.peek() {
+		case -1:
+			return nil
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipComment()
+			continue
+		}
+		if p.envrc && !p.isAssignment() {
+			p.skipComment()
+			continue
+		}
+		if err := p.assignment(); err != nil {
+			return err
+		}
+	}
+}
+
+// assignment parses a `KEY=value` line
+func (p *dotEnvParser) assignment() error {
+	line, col, start := p.line, p.col, p.pos
+	key := p.key()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		line, col, start = p.line, p.col, p.pos
+		key = p.key()
+	}
+	if key == "" {
+		return p.errorAt(line, col, "expected a key, got "+strconv.QuoteRune(p.peek()))
+	}
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return p.errorAt(p.line, p.col, "expected = after "+key)
+	}
+	p.next()
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	switch p.peek() {
+	case '"':
+		value, err = p.doubleQuoted()
+	case '\'':
+		value, err = p.singleQuoted()
+	default:
+		value, err = p.unquoted()
+	}
+	if err != nil {
+		return err
+	}
+	end := p.pos
+	for end > start && strings.ContainsRune(" \t\r", rune(p.src[end-1])) {
+		end--
+	}
+	p.skipSpaces()
+	switch r := p.peek(); r {
+	case '#':
+		p.skipComment()
+	case '\n', -1:
+	default:
+		return p.errorAt(p.line, p.col, "unexpected "+strconv.QuoteRune(r)+" after the value of "+key)
+	}
+	p.d.Vars[key] = value
+	p.d.lines[key] = line
+	p.d.spans[key] = span{start: start, end: end}
+	return nil
+}
+
+// isAssignment reports whether the next line is a `KEY=value` assignment,
+// without consuming it
+func (p *dotEnvParser) isAssignment() bool {
+	ahead := *p
+	if ahead.key() == "export" && (ahead.peek() == ' ' || ahead.peek() == '\t') {
+		ahead.skipSpaces()
+		ahead.key()
+	}
+	ahead.skipSpaces()
+	return ahead.pos > p.pos && ahead.peek() == '='
+}
+
+// key consumes a key made of letters, digits, `_`, `.` and `-`
+func (p *dotEnvParser) key() string {
+	start := p.pos
+	for isKeyRune(p.peek()) {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func isKeyRune(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// unquoted consumes a value up to the end of the line or a comment preceded
+// by a space
+func (p *dotEnvParser) unquoted() (string, error) {
+	var b strings.Builder
+	for {
+		r := p.peek()
+		if r == '\n' || r == -1 {
+			break
+		}
+		if r == '#' && p.pos > 0 && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		if r == '$' {
+			if err := p.interpolate(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteRune(p.next())
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// singleQuoted consumes a value between single quotes, taken literally
+func (p *dotEnvParser) singleQuoted() (string, error) {
+	line, col := p.line, p.col
+	p.next()
+	start := p.pos
+	for r := p.peek(); r != '\''; r = p.peek() {
+		if r == -1 {
+			return "", p.errorAt(line, col, "unterminated single-quoted value")
+		}
+		p.next()
+	}
+	value := p.src[start:p.pos]
+	p.next()
+	return value, nil
+}
+
+var escapes = map[rune]rune{'n': '\n', 'r': '\r', 't': '\t', '"': '"', '\\': '\\', '$': '$'}
+
+// doubleQuoted consumes a value between double quotes, with escapes and
+// interpolation
+func (p *dotEnvParser) doubleQuoted() (string, error) {
+	line, col := p.line, p.col
+	p.next()
+	var b strings.Builder
+	for {
+		switch r := p.peek(); r {
+		case -1:
+			return "", p.errorAt(line, col, "unterminated double-quoted value")
+		case '"':
+			p.next()
+			return b.String(), nil
+		case '\\':
+			escLine, escCol := p.line, p.col
+			p.next()
+			e, ok := escapes[p.peek()]
+			if !ok {
+				return "", p.errorAt(escLine, escCol, "invalid escape sequence \\"+string(p.peek()))
+			}
+			p.next()
+			b.WriteRune(e)
+		case '$':
+			if err := p.interpolate(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteRune(p.next())
+		}
+	}
+}
+
+// interpolate consumes a reference to a variable, `$KEY`, `${KEY}` or
+// `${KEY:-default}`, and writes its value to b. A `$` followed by anything
+// else is kept as is.
+func (p *dotEnvParser) interpolate(b *strings.Builder) error {
+	line, col := p.line, p.col
+	p.next()
+	if p.peek() != '{' {
+		start := p.pos
+		for r := p.peek(); r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (p.pos > start && r >= '0' && r <= '9'); r = p.peek() {
+			p.next()
+		}
+		if p.pos == start {
+			b.WriteByte('$')
+			return nil
+		}
+		value, _ := p.variable(p.src[start:p.pos])
+		b.WriteString(value)
+		return nil
+	}
+	p.next()
+	start := p.pos
+	for r := p.peek(); r != '}'; r = p.peek() {
+		if r == -1 || r == '\n' {
+			return p.errorAt(line, col, "unterminated ${")
+		}
+		p.next()
+	}
+	ref := p.src[start:p.pos]
+	p.next()
+	name, def := ref, ""
+	if i := strings.Index(ref, ":-"); i >= 0 {
+		name, def = ref[:i], ref[i+2:]
+	}
+	if name == "" {
+		return p.errorAt(line, col, "empty variable name in ${"+ref+"}")
+	}
+	if value, ok := p.variable(name); ok && value != "" {
+		b.WriteString(value)
+	} else {
+		b.WriteString(def)
+	}
+	return nil
+}
+
+// variable returns the value of a variable defined earlier in the file, or
+// in the process environment
+func (p *dotEnvParser) variable(name string) (string, bool) {
+	if value, ok := p.d.Vars[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// UpdateDotEnv sets the variables vars in the .env file at path, preserving
+// its comments, blank lines and order: variables defined in the file have
+// their value replaced where they are, the others are appended, sorted.
+// The file is created, readable by its owner only, if it doesn't exist.
+func UpdateDotEnv(path string, vars Map) error {
+	src, err := ioutil.ReadFile(path)
+	mode := os.FileMode(0600)
+	if err == nil {
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode()
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	out, err := updateDotEnv(string(src), path, vars)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(out), mode)
+}
+
+// updateDotEnv returns the content src of a .env file with vars set
+func updateDotEnv(src, name string, vars Map) (string, error) {
+	d, err := ParseDotEnv(strings.NewReader(src), name)
+	if err != nil {
+		return "", err
+	}
+	var replaced []string
+	for key := range vars {
+		if _, ok := d.spans[key]; ok {
+			replaced = append(replaced, key)
+		}
+	}
+	// replace from the end, so the spans before stay valid
+	sort.Slice(replaced, func(i, j int) bool { return d.spans[replaced[i]].start > d.spans[replaced[j]].start })
+	for _, key := range replaced {
+		sp := d.spans[key]
+		src = src[:sp.start] + key + "=" + quoteDotEnv(vars[key]) + src[sp.end:]
+	}
+	for _, key := range vars.Keys() {
+		if _, ok := d.spans[key]; ok {
+			continue
+		}
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		src += key + "=" + quoteDotEnv(vars[key]) + "\n"
+	}
+	return src, nil
+}
+
+// quoteDotEnv returns value as written in a .env file: as is if it has no
+// special characters, double-quoted otherwise
+func quoteDotEnv(value string) string {
+	plain := true
+	for _, r := range value {
+		if !isKeyRune(r) && !strings.ContainsRune("/:@,+=%", r) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
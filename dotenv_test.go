@@ -0,0 +1,176 @@
+package env_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTempFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "env")
+	assert.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	assert.NoError(t, err)
+	return f.Name()
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	path := writeTempFile(t, "# comment\nHOST=localhost\n\n PORT = 8080 \nEMPTY=\nURL=http://x?a=b\n")
+	defer os.Remove(path)
+
+	m, err := env.LoadDotEnv(path)
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{
+		"HOST":  "localhost",
+		"PORT":  "8080",
+		"EMPTY": "",
+		"URL":   "http://x?a=b",
+	}, m)
+}
+
+func TestLoadDotEnvInvalidLine(t *testing.T) {
+	path := writeTempFile(t, "HOST=localhost\nPORT\n")
+	defer os.Remove(path)
+
+	_, err := env.LoadDotEnv(path)
+	assert.EqualError(t, err, path+":2:5: expected = after PORT")
+}
+
+func TestLoadDotEnvMissingFile(t *testing.T) {
+	_, err := env.LoadDotEnv("/does/not/exist/.env")
+	assert.Error(t, err)
+}
+
+func TestParseDotEnv(t *testing.T) {
+	os.Setenv("REGION", "eu")
+	defer os.Clearenv()
+
+	d, err := env.ParseDotEnv(strings.NewReader(`# database
+export DB_HOST=db.local # inline comment
+DB_PORT = 5432
+DB_URL="postgres://${DB_HOST}:$DB_PORT/app"
+PASSWORD='p@ss$word#1'
+CERT="-----BEGIN-----
+abc\tdef
+-----END-----"
+ESCAPED="say \"hi\" \$HOME"
+HASH=a#b
+ZONE=${REGION}-1
+TIER=${TIER:-free}
+COST=$5
+`), ".env")
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{
+		"DB_HOST":  "db.local",
+		"DB_PORT":  "5432",
+		"DB_URL":   "postgres://db.local:5432/app",
+		"PASSWORD": "p@ss$word#1",
+		"CERT":     "-----BEGIN-----\nabc\tdef\n-----END-----",
+		"ESCAPED":  `say "hi" $HOME`,
+		"HASH":     "a#b",
+		"ZONE":     "eu-1",
+		"TIER":     "free",
+		"COST":     "$5",
+	}, d.Vars)
+	assert.Equal(t, 6, d.Line("CERT"))
+	assert.Equal(t, 10, d.Line("HASH"))
+}
+
+func TestParseDotEnvSyntaxErrors(t *testing.T) {
+	for content, msg := range map[string]string{
+		"A=1\n=2":              ".env:2:1: expected a key, got '='",
+		"A=1\nB=\"open\n\nC=3": ".env:2:3: unterminated double-quoted value",
+		"A='open":              ".env:1:3: unterminated single-quoted value",
+		"A=\"x\" y":            ".env:1:7: unexpected 'y' after the value of A",
+		"A=\"\\q\"":            ".env:1:4: invalid escape sequence \\q",
+		"A=${B":                ".env:1:3: unterminated ${",
+		"A=${}":                ".env:1:3: empty variable name in ${}",
+	} {
+		_, err := env.ParseDotEnv(strings.NewReader(content), ".env")
+		assert.EqualError(t, err, msg, content)
+	}
+}
+
+func TestDotEnvProvenance(t *testing.T) {
+	path := writeTempFile(t, "HOST=localhost\n\nPORT=http\n")
+	defer os.Remove(path)
+
+	d, err := env.OpenDotEnv(path)
+	assert.NoError(t, err)
+	var cfg struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	err = env.Parse(&cfg, env.WithLookuper(d))
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "http": invalid syntax (value from .env file `+path+` line 3)`)
+}
+
+func TestUpdateDotEnv(t *testing.T) {
+	path := writeTempFile(t, "# database\nexport DB_HOST=localhost # local only\nDB_PORT = 5432\n\n# api\nAPI_KEY='old'")
+	defer os.Remove(path)
+
+	err := env.UpdateDotEnv(path, env.Map{
+		"DB_HOST": "db.example.com",
+		"API_KEY": `n3w"$key`,
+		"TIMEOUT": "5s",
+		"BANNER":  "hello world\n",
+	})
+	assert.NoError(t, err)
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "# database\nexport DB_HOST=db.example.com # local only\nDB_PORT = 5432\n\n# api\nAPI_KEY=\"n3w\\\"\\$key\"\nBANNER=\"hello world\\n\"\nTIMEOUT=5s\n", string(b))
+
+	m, err := env.LoadDotEnv(path)
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{
+		"DB_HOST": "db.example.com",
+		"DB_PORT": "5432",
+		"API_KEY": `n3w"$key`,
+		"TIMEOUT": "5s",
+		"BANNER":  "hello world\n",
+	}, m)
+}
+
+func TestUpdateDotEnvNewFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := dir + "/.env"
+	assert.NoError(t, env.UpdateDotEnv(path, env.Map{"B": "2", "A": "1"}))
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "A=1\nB=2\n", string(b))
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+}
+
+func TestLoadEnvrc(t *testing.T) {
+	path := writeTempFile(t, `# direnv
+PATH_add bin
+dotenv .env.local
+use nix
+export DATABASE_URL="postgres://localhost/dev"
+export GOFLAGS=-mod=mod
+ROOT=$(pwd)/data
+layout go
+`)
+	defer os.Remove(path)
+
+	m, err := env.LoadEnvrc(path)
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{
+		"DATABASE_URL": "postgres://localhost/dev",
+		"GOFLAGS":      "-mod=mod",
+		"ROOT":         "$(pwd)/data",
+	}, m)
+
+	_, err = env.LoadDotEnv(path)
+	assert.EqualError(t, err, path+":2:10: expected = after PATH_add")
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// observation is the state of a variable read by Parse
+type observation struct {
+	value string
+	ok    bool
+}
+
+// parseObservations are the variables read by a parse and the Lookuper they
+// were read from
+type parseObservations struct {
+	lookuper Lookuper
+	vars     map[string]observation
+}
+
+// observed maps the pointers to the structs parsed with WithDriftDetection
+// to the variables read by their last parse
+var observed = struct {
+	sync.Mutex
+	byStruct map[interface{}]parseObservations
+}{byStruct: map[interface{}]parseObservations{}}
+
+// WithDriftDetection makes Parse remember the values of the variables it
+// reads, so `Drifted` can later report the ones that changed. The values
+// are kept until `ForgetDrift` is called for the struct, so it is meant for
+// long-lived configs; a Holder forgets the configs it replaces.
+func WithDriftDetection() Option {
+	return func(o *options) {
+		o.observed = map[string]observation{}
+	}
+}
+
+// observe records the state of key at parse time, if drift detection is
+// enabled
+func (o *options) observe(key, value string, ok bool) {
+	if o.observed != nil {
+		o.observed[key] = observation{value: value, ok: ok}
+	}
+}
+
+// saveObserved remembers the variables read for the struct pointed to by v
+func (o *options) saveObserved(v interface{}) {
+	if o.observed == nil {
+		return
+	}
+	observed.Lock()
+	defer observed.Unlock()
+	observed.byStruct[v] = parseObservations{lookuper: o.liveLookuper(), vars: o.observed}
+}
+
+// ForgetDrift forgets the variables read for cfg, the pointer given to Parse
+// with WithDriftDetection, once the config isn't used anymore
+func ForgetDrift(cfg interface{}) {
+	observed.Lock()
+	defer observed.Unlock()
+	delete(observed.byStruct, cfg)
+}
+
+// Drifted returns, sorted, the variables read by the last parse of cfg whose
+// value has changed since, e.g. because the environment was updated but the
+// service wasn't restarted. The variables are read again from the Lookuper
+// of the parse. cfg must be the pointer given to Parse with
+// WithDriftDetection.
+func Drifted(cfg interface{}) ([]string, error) {
+	if reflect.ValueOf(cfg).Kind() != reflect.Ptr {
+		return nil, ErrNotAStructPtr
+	}
+	observed.Lock()
+	then, ok := observed.byStruct[cfg]
+	observed.Unlock()
+	if !ok {
+		return nil, errors.New("Config wasn't parsed with WithDriftDetection")
+	}
+	var drifted []string
+	for key, was := range then.vars {
+		value, ok, err := lookupNow(then.lookuper, key)
+		if err != nil {
+			return nil, err
+		}
+		if value != was.value || ok != was.ok {
+			drifted = append(drifted, key)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+// lookupNow returns the current value of key in l
+func lookupNow(l Lookuper, key string) (string, bool, error) {
+	if cl, ok := l.(ContextLookuper); ok {
+		return cl.LookupContext(context.Background(), key)
+	}
+	value, ok := l.Lookup(key)
+	return value, ok, nil
+}
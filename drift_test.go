@@ -0,0 +1,94 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrifted(t *testing.T) {
+	type config struct {
+		Host    string `env:"HOST"`
+		Port    int    `env:"PORT" envDefault:"80"`
+		Debug   bool   `env:"DEBUG"`
+		Timeout string `env:"TIMEOUT"`
+	}
+	os.Setenv("HOST", "localhost")
+	os.Setenv("DEBUG", "true")
+	os.Setenv("TIMEOUT", "5s")
+	defer os.Clearenv()
+
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithDriftDetection()))
+	drifted, err := env.Drifted(&cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+
+	os.Setenv("HOST", "example.com")
+	os.Setenv("PORT", "8080")
+	os.Unsetenv("DEBUG")
+	drifted, err = env.Drifted(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DEBUG", "HOST", "PORT"}, drifted)
+
+	assert.NoError(t, env.Parse(&cfg, env.WithDriftDetection()))
+	drifted, err = env.Drifted(&cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+}
+
+func TestDriftedNotTracked(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg))
+	_, err := env.Drifted(&cfg)
+	assert.Equal(t, errors.New("Config wasn't parsed with WithDriftDetection"), err)
+	_, err = env.Drifted(cfg)
+	assert.Equal(t, env.ErrNotAStructPtr, err)
+}
+
+func TestDriftedCustomLookuper(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+	}
+	l := &mutableLookuper{m: env.Map{"HOST": "localhost"}}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l), env.WithDriftDetection()))
+	os.Setenv("HOST", "elsewhere")
+	defer os.Unsetenv("HOST")
+	drifted, err := env.Drifted(&cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+
+	l.set("HOST", "example.com")
+	drifted, err = env.Drifted(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HOST"}, drifted)
+
+	env.ForgetDrift(&cfg)
+	_, err = env.Drifted(&cfg)
+	assert.Equal(t, errors.New("Config wasn't parsed with WithDriftDetection"), err)
+}
+
+func TestHolderForgetsDrift(t *testing.T) {
+	l := &mutableLookuper{m: env.Map{"PORT": "8080", "LEVEL": "info"}}
+	h, err := env.NewHolder(func() interface{} { return &reloadConfig{} }, env.WithLookuper(l), env.WithDriftDetection())
+	assert.NoError(t, err)
+	first := h.Get()
+	_, err = env.Drifted(first)
+	assert.NoError(t, err)
+
+	l.set("LEVEL", "debug")
+	_, err = h.Reload()
+	assert.NoError(t, err)
+	_, err = env.Drifted(first)
+	assert.Error(t, err)
+	drifted, err := env.Drifted(h.Get())
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+}
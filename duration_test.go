@@ -0,0 +1,60 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationBounds(t *testing.T) {
+	type config struct {
+		Timeout  time.Duration  `env:"TIMEOUT" envMinDuration:"100ms" envMaxDuration:"1m"`
+		Interval *time.Duration `env:"INTERVAL" envMinDuration:"1s"`
+		Grace    time.Duration  `env:"GRACE" envMaxDuration:"30s" envDefault:"10s"`
+	}
+
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"TIMEOUT": "1m", "INTERVAL": "5s"})))
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.Equal(t, 5*time.Second, *cfg.Interval)
+	assert.Equal(t, 10*time.Second, cfg.Grace)
+
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{"TIMEOUT": "5ms", "INTERVAL": "500ms", "GRACE": "1m"}))
+	assert.EqualError(t, err, "Invalid duration 5ms for TIMEOUT, expected between 100ms and 1m0s. "+
+		"Invalid duration 500ms for INTERVAL, expected at least 1s. "+
+		"Invalid duration 1m for GRACE, expected at most 30s")
+}
+
+func TestExtendedDurationBounds(t *testing.T) {
+	type config struct {
+		Retention time.Duration `env:"RETENTION,extendedDuration" envMinDuration:"1d" envMaxDuration:"2w"`
+		TTL       time.Duration `env:"TTL" envMaxDuration:"1d"`
+	}
+
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"RETENTION": "1w", "TTL": "12h"})))
+	assert.Equal(t, 7*24*time.Hour, cfg.Retention)
+	assert.Equal(t, 12*time.Hour, cfg.TTL)
+
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{"RETENTION": "12h", "TTL": "1d"}), env.WithExtendedDurations())
+	assert.EqualError(t, err, "Invalid duration 12h for RETENTION, expected between 24h0m0s and 336h0m0s")
+}
+
+func TestDurationBoundsInvalid(t *testing.T) {
+	type notDuration struct {
+		Port int `env:"PORT" envMinDuration:"1s"`
+	}
+	type invalid struct {
+		Timeout time.Duration `env:"TIMEOUT" envMaxDuration:"soon"`
+	}
+	type inverted struct {
+		Timeout time.Duration `env:"TIMEOUT" envMinDuration:"1m" envMaxDuration:"1s"`
+	}
+	empty := env.Map{}
+	assert.Equal(t, errors.New("Invalid envMinDuration 1s, the field isn't a duration"), env.Parse(&notDuration{}, env.WithLookuper(empty)))
+	assert.Equal(t, errors.New("Invalid envMaxDuration soon, expected a duration"), env.Parse(&invalid{}, env.WithLookuper(empty)))
+	assert.Equal(t, errors.New("Invalid envMinDuration 1m0s, greater than envMaxDuration 1s"), env.Parse(&inverted{}, env.WithLookuper(empty)))
+}
@@ -0,0 +1,93 @@
+package env
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted values, followed by the base64 encoded
+// ciphertext
+const encryptedPrefix = "enc:v1:"
+
+// Decrypter decrypts the ciphertext of an encrypted value, e.g. by calling a
+// KMS. The context is the one given to ParseContext.
+type Decrypter func(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+// WithDecrypter decrypts values of the form `enc:v1:<base64>` with d before
+// they are parsed, so semi-sensitive values can be stored in otherwise
+// plaintext environments or ConfigMaps. Without a Decrypter, such values
+// are errors.
+func WithDecrypter(d Decrypter) Option {
+	return func(o *options) {
+		o.decrypter = d
+	}
+}
+
+// WithDecryptionKey decrypts values of the form `enc:v1:<base64>` with
+// AES-GCM using key, which must be 16, 24 or 32 bytes long. Such values are
+// produced by `Encrypt`.
+func WithDecryptionKey(key []byte) Option {
+	return WithDecrypter(func(ctx context.Context, ciphertext []byte) ([]byte, error) {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(ciphertext) < aead.NonceSize() {
+			return nil, errors.New("Ciphertext too short")
+		}
+		nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+		return aead.Open(nil, nonce, sealed, nil)
+	})
+}
+
+// Encrypt encrypts plaintext with AES-GCM using key, returning a value of
+// the form `enc:v1:<base64>` that `WithDecryptionKey` decrypts
+func Encrypt(key, plaintext []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// decrypt returns the plaintext of value, the value of key, if encrypted
+func (o *options) decrypt(key, value string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	if o.decrypter == nil {
+		return "", errors.New("Environment variable " + key + " is encrypted but no decryption key is set")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(value[len(encryptedPrefix):])
+	if err != nil {
+		return "", errors.New("Cannot decrypt environment variable " + key + ": " + err.Error())
+	}
+	plaintext, err := o.decrypter(o.ctx, ciphertext)
+	if err != nil {
+		return "", errors.New("Cannot decrypt environment variable " + key + ": " + err.Error())
+	}
+	return string(plaintext), nil
+}
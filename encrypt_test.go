@@ -0,0 +1,46 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptionKey(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN"`
+		Plain string `env:"PLAIN"`
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := env.Encrypt(key, []byte("s3cr3t"))
+	assert.NoError(t, err)
+	lookuper := env.WithLookuper(env.Map{"TOKEN": encrypted, "PLAIN": "plain"})
+
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, lookuper, env.WithDecryptionKey(key)))
+	assert.Equal(t, config{Token: "s3cr3t", Plain: "plain"}, cfg)
+
+	assert.EqualError(t, env.Parse(&cfg, lookuper), "Environment variable TOKEN is encrypted but no decryption key is set")
+	assert.EqualError(t, env.Parse(&cfg, lookuper, env.WithDecryptionKey([]byte("fedcba9876543210fedcba9876543210"))),
+		"Cannot decrypt environment variable TOKEN: cipher: message authentication failed")
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"TOKEN": "enc:v1:!"}), env.WithDecryptionKey(key)),
+		"Cannot decrypt environment variable TOKEN: illegal base64 data at input byte 0")
+}
+
+func TestDecrypter(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN"`
+	}
+	kms := func(ctx context.Context, ciphertext []byte) ([]byte, error) {
+		if string(ciphertext) != "sealed" {
+			return nil, errors.New("unknown ciphertext")
+		}
+		return []byte("opened"), nil
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithDecrypter(kms), env.WithLookuper(env.Map{"TOKEN": "enc:v1:c2VhbGVk"})))
+	assert.Equal(t, "opened", cfg.Token)
+}
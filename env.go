@@ -1,13 +1,11 @@
 package env
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
-	"time"
 )
 
 var (
@@ -18,13 +16,6 @@ var (
 	ErrUnsupportedType = errors.New("Type is not supported")
 	// ErrUnsupportedSliceType if the slice element type is not supported by env
 	ErrUnsupportedSliceType = errors.New("Unsupported slice type")
-	// Friendly names for reflect types
-	sliceOfInts     = reflect.TypeOf([]int(nil))
-	sliceOfInt64s   = reflect.TypeOf([]int64(nil))
-	sliceOfStrings  = reflect.TypeOf([]string(nil))
-	sliceOfBools    = reflect.TypeOf([]bool(nil))
-	sliceOfFloat32s = reflect.TypeOf([]float32(nil))
-	sliceOfFloat64s = reflect.TypeOf([]float64(nil))
 )
 
 // CustomParsers is a friendly name for the type that `ParseWithFuncs()` accepts
@@ -35,21 +26,39 @@ type ParserFunc func(v string) (interface{}, error)
 
 // Parse parses a struct containing `env` tags and loads its values from
 // environment variables.
-func Parse(v interface{}) error {
-	ptrRef := reflect.ValueOf(v)
-	if ptrRef.Kind() != reflect.Ptr {
-		return ErrNotAStructPtr
-	}
-	ref := ptrRef.Elem()
-	if ref.Kind() != reflect.Struct {
-		return ErrNotAStructPtr
-	}
-	return doParse(ref, make(map[reflect.Type]ParserFunc, 0))
+//
+// Fields are parsed in the order they are declared, nested structs depth
+// first, and their errors are joined in that order, followed by the errors
+// about the whole config, such as unknown variables, sorted, and by a
+// summary of the missing required variables when there are several. The
+// same config and environment thus always give the same error.
+func Parse(v interface{}, opts ...Option) error {
+	return ParseContext(context.Background(), v, opts...)
 }
 
 // ParseWithFuncs is the same as `Parse` except it also allows the user to pass
 // in custom parsers.
-func ParseWithFuncs(v interface{}, funcMap CustomParsers) error {
+func ParseWithFuncs(v interface{}, funcMap CustomParsers, opts ...Option) error {
+	return ParseContext(context.Background(), v, append(opts, WithFuncs(funcMap))...)
+}
+
+// ParseFields is the same as `Parse` except only the given fields, and the
+// fields nested in them, are parsed, see `WithFields`
+func ParseFields(v interface{}, paths ...string) error {
+	return Parse(v, WithFields(paths...))
+}
+
+// ParseWithPrefix is the same as `Parse` except prefix is prepended to every
+// key, so several copies of a struct can be loaded from namespaced
+// variables, see `WithPrefix`
+func ParseWithPrefix(v interface{}, prefix string, opts ...Option) error {
+	return Parse(v, append(opts, WithPrefix(prefix))...)
+}
+
+// ParseContext is the same as `Parse` except the given context bounds the
+// lookups: parsing stops as soon as ctx is done, and ctx is passed down to
+// Lookupers that implement ContextLookuper.
+func ParseContext(ctx context.Context, v interface{}, opts ...Option) error {
 	ptrRef := reflect.ValueOf(v)
 	if ptrRef.Kind() != reflect.Ptr {
 		return ErrNotAStructPtr
@@ -58,279 +67,434 @@ func ParseWithFuncs(v interface{}, funcMap CustomParsers) error {
 	if ref.Kind() != reflect.Struct {
 		return ErrNotAStructPtr
 	}
-	return doParse(ref, funcMap)
+	o := newOptions(ctx, opts)
+	if err := o.checkTags(ref.Type()); err != nil {
+		return err
+	}
+	ctx, span := o.startSpan("env.Parse")
+	o.ctx = ctx
+	o.prefetch(ref)
+	o.visiting[ptrRef.Pointer()] = ""
+	err := o.safeParse(ref)
+	if selectErr := o.unmatchedFields(); selectErr != nil && o.ctx.Err() == nil {
+		err = joinErrors(err, selectErr)
+	}
+	if specErr := o.unusedSpec(); specErr != nil && o.ctx.Err() == nil {
+		err = joinErrors(err, specErr)
+	}
+	if auditErr := o.auditNamespaces(); auditErr != nil && o.ctx.Err() == nil {
+		err = joinErrors(err, auditErr)
+	}
+	if summary := o.requiredSummary(); summary != nil {
+		err = joinErrors(err, summary)
+	}
+	o.saveObserved(v)
+	span.SetAttribute("env.fields", o.fields)
+	span.SetAttribute("env.errors", o.errors)
+	span.End(err)
+	return err
 }
 
-func doParse(ref reflect.Value, funcMap CustomParsers) error {
+// doParse fills the fields of the struct ref. path is the path of ref's
+// fields in the parsed struct and prefix is prepended to their keys.
+func doParse(ref reflect.Value, path, prefix string, o *options) error {
 	refType := ref.Type()
 	var errorList []string
+	// errors of nested structs are counted in o.errors by the doParse call
+	// returning them, so they are only counted once
+	counted := 0
+	nestedError := func(err error, errorsBefore int) {
+		errorList = append(errorList, err.Error())
+		if o.errors > errorsBefore {
+			counted++
+		}
+	}
 
 	for i := 0; i < refType.NumField(); i++ {
-		if reflect.Ptr == ref.Field(i).Kind() && !ref.Field(i).IsNil() && ref.Field(i).CanSet() {
-			err := Parse(ref.Field(i).Interface())
-			if nil != err {
-				return err
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		field, fieldPath := ref.Field(i), path+refType.Field(i).Name
+		o.currentPath, o.currentKey = fieldPath, ""
+		info, err := o.prefixedInfo(refType.Field(i), fieldPath, prefix)
+		if err != nil {
+			errorList = append(errorList, err.Error())
+			continue
+		}
+		o.currentKey = info.key
+		if !o.selected(fieldPath) {
+			continue
+		}
+		if o.explain != nil && o.explain.path == fieldPath {
+			o.explain.info, o.explain.field = info, field
+		}
+		kind := o.kindOf(field, info)
+		if kind == kindRuntime {
+			// channels, funcs and locks are skipped, unless bound to a
+			// variable by mistake
+			if info.key != "" {
+				errorList = append(errorList, runtimeFieldError(fieldPath, info.key, field.Type()).Error())
 			}
 			continue
 		}
-		value, err := get(refType.Field(i))
+		if kind == kindStructPtr {
+			if field.Elem().Kind() != reflect.Struct {
+				return ErrNotAStructPtr
+			}
+			errorsBefore := o.errors
+			err := o.enter(field, fieldPath)
+			if err == nil {
+				err = doParse(field.Elem(), fieldPath+".", o.nestedPrefix(prefix, refType.Field(i).Name, info), o)
+				o.leave(field)
+			}
+			if err != nil {
+				nestedError(err, errorsBefore)
+			}
+			continue
+		}
+		if kind == kindStruct {
+			errorsBefore := o.errors
+			err := o.enter(field, fieldPath)
+			if err == nil {
+				err = doParse(field, fieldPath+".", o.nestedPrefix(prefix, refType.Field(i).Name, info), o)
+			}
+			if err != nil {
+				nestedError(err, errorsBefore)
+			}
+			continue
+		}
+		if kind == kindImplementation {
+			errorsBefore := o.errors
+			source, err := parseImplementation(field, info, fieldPath, prefix, o)
+			if err != nil {
+				nestedError(err, errorsBefore)
+			}
+			o.record(fieldPath, info, source, field, err)
+			continue
+		}
+		if kind == kindWildcard {
+			err := parseWildcard(field, info, fieldPath, o)
+			if err != nil {
+				errorList = append(errorList, err.Error())
+			}
+			o.record(fieldPath, info, SourceEnvironment, field, err)
+			continue
+		}
+		if kind == kindIndexed {
+			errorsBefore := o.errors
+			if err := parseIndexed(field, info, fieldPath, o); err != nil {
+				nestedError(err, errorsBefore)
+			}
+			continue
+		}
+		if kind == kindKeyed {
+			errorsBefore := o.errors
+			if err := parseKeyed(field, info, fieldPath, o); err != nil {
+				nestedError(err, errorsBefore)
+			}
+			continue
+		}
+		if kind == kindLazy {
+			if err := o.bind(info.key, fieldPath); err != nil {
+				errorList = append(errorList, err.Error())
+				continue
+			}
+			lazy, _ := asLazy(field)
+			lazy.setResolver(o.resolver(info))
+			continue
+		}
+		if kind == kindRefreshing {
+			err := o.bind(info.key, fieldPath)
+			if err == nil {
+				refreshing, _ := asRefreshing(field)
+				first := func(t reflect.Type) (reflect.Value, error) {
+					return resolveAs(info, t, o)
+				}
+				err = refreshing.init(first, o.resolver(info), info.refresh)
+			}
+			if err != nil {
+				errorList = append(errorList, err.Error())
+			}
+			continue
+		}
+		if kind == kindFeatures {
+			source, err := parseFeatures(field, info, fieldPath, prefix, o)
+			if err != nil {
+				errorList = append(errorList, err.Error())
+			}
+			o.record(fieldPath, info, source, field, err)
+			continue
+		}
+		if kind == kindKeyPair {
+			source, err := parseKeyPair(field, info, fieldPath, o)
+			if err != nil {
+				errorList = append(errorList, err.Error())
+			}
+			o.record(fieldPath, info, source, field, err)
+			continue
+		}
+		value, source, err := get(info, fieldPath, o)
 		if err != nil {
 			errorList = append(errorList, err.Error())
+			o.record(fieldPath, info, source, field, err)
 			continue
 		}
 		if value == "" {
+			o.record(fieldPath, info, source, field, nil)
+			continue
+		}
+		if info.nullValue != "" && value == info.nullValue {
+			field.Set(reflect.Zero(field.Type()))
+			o.fieldParsed(info.key)
+			o.record(fieldPath, info, source, field, nil)
 			continue
 		}
-		if err := set(ref.Field(i), refType.Field(i), value, funcMap); err != nil {
+		previous := reflect.New(field.Type()).Elem()
+		previous.Set(field)
+		err = info.checkOneOf(value, field.Type())
+		if err == nil {
+			err = set(field, value, o.conversion(info))
+		}
+		if err == nil {
+			err = info.checkDuration(field, value)
+		}
+		if err != nil && (info.onError == onErrorIgnore || info.onError == onErrorDefault) && source != SourceDefault {
+			if forbidden := o.checkFallback(info); forbidden != nil {
+				err = errors.New(err.Error() + ". " + forbidden.Error())
+			} else {
+				field.Set(previous)
+				source, err = fallback(field, info, o, err)
+				o.recordWarning(fieldPath, info, source, field, err)
+				continue
+			}
+		}
+		if err != nil {
+			err = o.withProvenance(err, info.key, source)
 			errorList = append(errorList, err.Error())
+			o.record(fieldPath, info, source, field, err)
 			continue
 		}
+		o.fieldParsed(info.key)
+		if warning := info.clamp(field, value); warning != nil {
+			o.recordWarning(fieldPath, info, source, field, warning)
+			continue
+		}
+		o.record(fieldPath, info, source, field, nil)
 	}
 	if len(errorList) == 0 {
 		return nil
 	}
+	o.errors += len(errorList) - counted
 	return errors.New(strings.Join(errorList, ". "))
 }
 
-func get(field reflect.StructField) (string, error) {
-	var (
-		val string
-		err error
-	)
-
-	key, opts := parseKeyForOption(field.Tag.Get("env"))
-
-	defaultValue := field.Tag.Get("envDefault")
-	val = getOr(key, defaultValue)
-
-	if len(opts) > 0 {
-		for _, opt := range opts {
-			// The only option supported is "required".
-			switch opt {
-			case "":
-				break
-			case "required":
-				val, err = getRequired(key)
-			default:
-				err = errors.New("Env tag option " + opt + " not supported.")
-			}
-		}
+// prefixedInfo returns the tag info of field, whose path is fieldPath, with
+// its keys prefixed by prefix
+func (o *options) prefixedInfo(field reflect.StructField, fieldPath, prefix string) (tagInfo, error) {
+	info, err := o.fieldInfo(field, fieldPath)
+	if err != nil {
+		return info, err
 	}
-
-	return val, err
+	if info.key != "" {
+		info.key = o.transformKey(prefix + info.key)
+	}
+	if info.privateKey != "" {
+		info.privateKey = o.transformKey(prefix + info.privateKey)
+	}
+	return info, nil
 }
 
-// split the env tag's key into the expected key and desired option, if any.
-func parseKeyForOption(key string) (string, []string) {
-	opts := strings.Split(key, ",")
-	return opts[0], opts[1:]
-}
+// fieldKind is how doParse reads a field
+type fieldKind int
+
+const (
+	// read from the single variable of its key, see get
+	kindValue fieldKind = iota
+	// channels, funcs and locks, see isRuntimeType
+	kindRuntime
+	// nested structs, and pointers to them, whose fields are parsed
+	kindStructPtr
+	kindStruct
+	// interfaces read by parseImplementation
+	kindImplementation
+	// maps captured by the pattern of their key
+	kindWildcard
+	// slices and maps of structs, read from indexed or keyed variables
+	kindIndexed
+	kindKeyed
+	// resolved when read rather than by Parse
+	kindLazy
+	kindRefreshing
+	kindFeatures
+	kindKeyPair
+)
 
-func getRequired(key string) (string, error) {
-	if value, ok := os.LookupEnv(key); ok {
-		return value, nil
+// kindOf returns how doParse reads field, described by info
+func (o *options) kindOf(field reflect.Value, info tagInfo) fieldKind {
+	switch {
+	case isRuntimeType(field.Type(), o.converters):
+		return kindRuntime
+	case info.key == "" && field.Kind() == reflect.Ptr && !field.IsNil() && field.CanSet():
+		return kindStructPtr
+	case info.key == "" && field.Kind() == reflect.Struct && field.CanSet() && isStruct(field.Type(), o.converters):
+		return kindStruct
+	case info.key == "":
+		return kindValue
+	case implementationsOf(field.Type()) != nil:
+		return kindImplementation
+	case isWildcard(info.key):
+		return kindWildcard
+	case isStructSlice(field.Type(), o.converters):
+		return kindIndexed
+	case isStructMap(field.Type(), o.converters):
+		return kindKeyed
 	}
-	// We do not use fmt.Errorf to avoid another import.
-	return "", errors.New("Required environment variable " + key + " is not set")
-}
-
-func getOr(key, defaultValue string) string {
-	value, ok := os.LookupEnv(key)
-	if ok {
-		return value
+	if _, ok := asLazy(field); ok {
+		return kindLazy
+	}
+	if _, ok := asRefreshing(field); ok {
+		return kindRefreshing
 	}
-	return defaultValue
+	switch field.Type() {
+	case featuresType:
+		return kindFeatures
+	case keyPairType:
+		return kindKeyPair
+	}
+	return kindValue
 }
 
-func set(field reflect.Value, refType reflect.StructField, value string, funcMap CustomParsers) error {
-	switch field.Kind() {
-	case reflect.Slice:
-		separator := refType.Tag.Get("envSeparator")
-		return handleSlice(field, value, separator)
-	case reflect.String:
-		field.SetString(value)
-	case reflect.Bool:
-		bvalue, err := strconv.ParseBool(value)
-		if err != nil {
-			return err
-		}
-		field.SetBool(bvalue)
-	case reflect.Int:
-		intValue, err := strconv.ParseInt(value, 10, 32)
-		if err != nil {
-			return err
-		}
-		field.SetInt(intValue)
-	case reflect.Uint:
-		uintValue, err := strconv.ParseUint(value, 10, 32)
-		if err != nil {
-			return err
-		}
-		field.SetUint(uintValue)
-	case reflect.Float32:
-		v, err := strconv.ParseFloat(value, 32)
-		if err != nil {
-			return err
-		}
-		field.SetFloat(v)
-	case reflect.Float64:
-		v, err := strconv.ParseFloat(value, 64)
+// joinErrors combines errors the same way field errors are combined
+func joinErrors(errs ...error) error {
+	var errorList []string
+	for _, err := range errs {
 		if err != nil {
-			return err
-		}
-		field.Set(reflect.ValueOf(v))
-	case reflect.Int64:
-		if refType.Type.String() == "time.Duration" {
-			dValue, err := time.ParseDuration(value)
-			if err != nil {
-				return err
-			}
-			field.Set(reflect.ValueOf(dValue))
-		} else {
-			intValue, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				return err
-			}
-			field.SetInt(intValue)
+			errorList = append(errorList, err.Error())
 		}
-	case reflect.Struct:
-		return handleStruct(field, refType, value, funcMap)
-	default:
-		return ErrUnsupportedType
 	}
-	return nil
+	if len(errorList) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errorList, ". "))
 }
 
-func handleStruct(field reflect.Value, refType reflect.StructField, value string, funcMap CustomParsers) error {
-	// Does the custom parser func map contain this type?
-	parserFunc, ok := funcMap[field.Type()]
-	if !ok {
-		// Map does not contain a custom parser for this type
-		return ErrUnsupportedType
+// requiredSummary sums up the missing required variables when there are
+// several, e.g. `2 required variables missing: HOST, PORT`, so operators can
+// set them all at once
+func (o *options) requiredSummary() error {
+	if len(o.missing) < 2 {
+		return nil
 	}
+	return errors.New(strconv.Itoa(len(o.missing)) + " required variables missing: " + strings.Join(o.missing, ", "))
+}
 
-	// Call on the custom parser func
-	data, err := parserFunc(value)
-	if err != nil {
-		return fmt.Errorf("Custom parser error: %v", err)
+// get returns the value of the field described by info and where it comes
+// from, one of the Source constants
+func get(info tagInfo, fieldPath string, o *options) (string, string, error) {
+	if info.key == "" && info.command != nil {
+		value, err := o.runCommand(info)
+		return value, SourceCommand, err
 	}
-
-	// Set the field to the data returned by the customer parser func
-	rv := reflect.ValueOf(data)
-	field.Set(rv)
-
-	return nil
+	if info.key == "" {
+		return info.defaultValue, sourceOf(info.defaultValue), nil
+	}
+	if err := o.bind(info.key, fieldPath); err != nil {
+		return "", SourceUnset, err
+	}
+	return resolve(info, o)
 }
 
-func handleSlice(field reflect.Value, value, separator string) error {
-	if separator == "" {
-		separator = ","
+// resolve returns the value of the variable info.key from the first source
+// holding it, or its default, and where it comes from
+func resolve(info tagInfo, o *options) (string, string, error) {
+	value, source, err := o.lookupSources(info)
+	o.explainLookup(value, source, err)
+	if err != nil {
+		return "", SourceUnset, err
 	}
-
-	splitData := strings.Split(value, separator)
-
-	switch field.Type() {
-	case sliceOfStrings:
-		field.Set(reflect.ValueOf(splitData))
-	case sliceOfInts:
-		intData, err := parseInts(splitData)
-		if err != nil {
-			return err
+	if source == SourceDefault {
+		if err := o.checkDefault(info); err != nil {
+			return "", SourceUnset, err
 		}
-		field.Set(reflect.ValueOf(intData))
-	case sliceOfInt64s:
-		int64Data, err := parseInt64s(splitData)
-		if err != nil {
-			return err
-		}
-		field.Set(reflect.ValueOf(int64Data))
-
-	case sliceOfFloat32s:
-		data, err := parseFloat32s(splitData)
-		if err != nil {
-			return err
+		o.defaultUsed(info.key)
+	}
+	if source != SourceUnset {
+		if source != SourceDefault && (info.trim || o.trim) {
+			value = trim(value)
 		}
-		field.Set(reflect.ValueOf(data))
-	case sliceOfFloat64s:
-		data, err := parseFloat64s(splitData)
-		if err != nil {
-			return err
+		if info.unescapeNewlines {
+			value = unescapeNewlines(value)
 		}
-		field.Set(reflect.ValueOf(data))
-	case sliceOfBools:
-		boolData, err := parseBools(splitData)
+		return value, source, nil
+	}
+	if info.command != nil {
+		value, err := o.runCommand(info)
 		if err != nil {
-			return err
+			return "", SourceUnset, err
 		}
-		field.Set(reflect.ValueOf(boolData))
-	default:
-		return ErrUnsupportedSliceType
+		return value, SourceCommand, nil
 	}
-	return nil
-}
-
-func parseInts(data []string) ([]int, error) {
-	var intSlice []int
-
-	for _, v := range data {
-		intValue, err := strconv.ParseInt(v, 10, 32)
+	if info.required && o.prompter != nil {
+		value, err := o.prompter(info.key, info.secret)
 		if err != nil {
-			return nil, err
+			return "", SourceUnset, err
+		}
+		if value != "" {
+			return value, SourcePrompt, nil
 		}
-		intSlice = append(intSlice, int(intValue))
 	}
-	return intSlice, nil
-}
-
-func parseInt64s(data []string) ([]int64, error) {
-	var intSlice []int64
-
-	for _, v := range data {
-		intValue, err := strconv.ParseInt(v, 10, 64)
-		if err != nil {
-			return nil, err
+	if info.required {
+		o.requiredMissing(info.key)
+		// We do not use fmt.Errorf to avoid another import.
+		return "", SourceUnset, errors.New("Required environment variable " + info.key + " is not set")
+	}
+	if info.defaultValue != "" {
+		if err := o.checkDefault(info); err != nil {
+			return "", SourceUnset, err
 		}
-		intSlice = append(intSlice, int64(intValue))
+		o.defaultUsed(info.key)
 	}
-	return intSlice, nil
+	if info.unescapeNewlines {
+		return unescapeNewlines(info.defaultValue), sourceOf(info.defaultValue), nil
+	}
+	return info.defaultValue, sourceOf(info.defaultValue), nil
 }
 
-func parseFloat32s(data []string) ([]float32, error) {
-	var float32Slice []float32
-
-	for _, v := range data {
-		data, err := strconv.ParseFloat(v, 32)
-		if err != nil {
-			return nil, err
-		}
-		float32Slice = append(float32Slice, float32(data))
+// fallback handles the error parsing a field tagged with `envOnError:"ignore"`
+// or `envOnError:"default"`: the field is left untouched or set to its
+// default. It returns the source of the field's value and the warning.
+func fallback(field reflect.Value, info tagInfo, o *options, err error) (string, error) {
+	if info.onError == onErrorIgnore || info.defaultValue == "" {
+		return SourceUnset, errors.New("Invalid value for " + info.key + " ignored: " + err.Error())
+	}
+	if defaultErr := set(field, info.defaultValue, o.conversion(info)); defaultErr != nil {
+		return SourceUnset, errors.New("Invalid value for " + info.key + " ignored: " + err.Error() + ". " + defaultErr.Error())
 	}
-	return float32Slice, nil
+	return SourceDefault, errors.New("Invalid value for " + info.key + " replaced by the default: " + err.Error())
 }
 
-func parseFloat64s(data []string) ([]float64, error) {
-	var float64Slice []float64
-
-	for _, v := range data {
-		data, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return nil, err
-		}
-		float64Slice = append(float64Slice, float64(data))
+// sourceOf returns the source of a field whose value is the given default
+func sourceOf(defaultValue string) string {
+	if defaultValue == "" {
+		return SourceUnset
 	}
-	return float64Slice, nil
+	return SourceDefault
 }
 
-func parseBools(data []string) ([]bool, error) {
-	var boolSlice []bool
-
-	for _, v := range data {
-		bvalue, err := strconv.ParseBool(v)
-		if err != nil {
-			return nil, err
-		}
+// unescapeNewlines replaces the `\n` sequences of value by newlines and
+// its CRLF line endings by LF, so that multi-line values such as PEM
+// certificates can be set as single-line variables
+func unescapeNewlines(value string) string {
+	value = strings.Replace(value, "\r\n", "\n", -1)
+	value = strings.Replace(value, `\r\n`, "\n", -1)
+	return strings.Replace(value, `\n`, "\n", -1)
+}
 
-		boolSlice = append(boolSlice, bvalue)
+// trim strips surrounding whitespace and matching quotes from value
+func trim(value string) string {
+	value = strings.TrimSpace(value)
+	if n := len(value); n >= 2 && (value[0] == '"' || value[0] == '\'') && value[n-1] == value[0] {
+		value = strings.TrimSpace(value[1 : n-1])
 	}
-	return boolSlice, nil
+	return value
 }
@@ -1,11 +1,18 @@
 package env_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"net/http"
+	"net/mail"
 	"os"
+	"os/exec"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -231,6 +238,46 @@ func TestErrorRequiredNotSet(t *testing.T) {
 	assert.Error(t, env.Parse(cfg))
 }
 
+func TestRequiredSummary(t *testing.T) {
+	type database struct {
+		Host string `env:"DB_HOST,required"`
+		User string `env:"DB_USER,required"`
+	}
+	type config struct {
+		Port     int `env:"PORT,required"`
+		Database database
+		Token    string `env:"TOKEN,required"`
+	}
+
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{"DB_USER": "app"}))
+	assert.EqualError(t, err, "Required environment variable PORT is not set. "+
+		"Required environment variable DB_HOST is not set. "+
+		"Required environment variable TOKEN is not set. "+
+		"3 required variables missing: PORT, DB_HOST, TOKEN")
+
+	err = env.Parse(&config{}, env.WithLookuper(env.Map{"PORT": "80", "DB_HOST": "db", "DB_USER": "app"}))
+	assert.EqualError(t, err, "Required environment variable TOKEN is not set")
+}
+
+func TestRequiredSummaryNestedPointer(t *testing.T) {
+	type database struct {
+		URL  string `env:"URL,required"`
+		User string `env:"USER,required"`
+	}
+	type config struct {
+		DB   *database `envPrefix:"DB_"`
+		Port int       `env:"PORT,required"`
+		Host string    `env:"HOST,required"`
+	}
+
+	err := env.Parse(&config{DB: &database{}}, env.WithLookuper(env.Map{}))
+	assert.EqualError(t, err, "Required environment variable DB_URL is not set. "+
+		"Required environment variable DB_USER is not set. "+
+		"Required environment variable PORT is not set. "+
+		"Required environment variable HOST is not set. "+
+		"4 required variables missing: DB_URL, DB_USER, PORT, HOST")
+}
+
 func TestCustomParser(t *testing.T) {
 	type foo struct {
 		name string
@@ -294,6 +341,31 @@ func TestCustomParserError(t *testing.T) {
 	assert.Equal(t, err.Error(), "Custom parser error: something broke")
 }
 
+func TestCustomParserWrongType(t *testing.T) {
+	type foo struct {
+		name string
+	}
+	type config struct {
+		Var  foo   `env:"VAR"`
+		Vars []foo `env:"VARS"`
+		Nil  *foo  `env:"NIL"`
+	}
+	parsers := env.CustomParsers{
+		reflect.TypeOf(foo{}): func(v string) (interface{}, error) {
+			return &foo{name: v}, nil
+		},
+		reflect.TypeOf(&foo{}): func(v string) (interface{}, error) {
+			return nil, nil
+		},
+	}
+
+	cfg := &config{}
+	err := env.ParseWithFuncs(cfg, parsers, env.WithLookuper(env.Map{"VAR": "a", "VARS": "b,c", "NIL": "d"}))
+	assert.EqualError(t, err, "Parser of field Var (VAR) returned *env_test.foo, expected env_test.foo. "+
+		"Parser of field Vars (VARS) returned *env_test.foo, expected env_test.foo. "+
+		"Parser of field Nil (NIL) returned nil, expected *env_test.foo")
+}
+
 func TestUnsupportedStructType(t *testing.T) {
 	type config struct {
 		Foo http.Client `env:"FOO"`
@@ -330,6 +402,690 @@ func TestErrorOptionNotRecognized(t *testing.T) {
 
 }
 
+func TestParseWithLookuper(t *testing.T) {
+	cfg := Config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"somevar": "fromMap",
+		"PORT":    "9000",
+	})))
+	assert.Equal(t, "fromMap", cfg.Some)
+	assert.Equal(t, 9000, cfg.Port)
+}
+
+type ctxLookuper struct {
+	ctx context.Context
+}
+
+func (l *ctxLookuper) Lookup(key string) (string, bool) {
+	v, ok, _ := l.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (l *ctxLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	l.ctx = ctx
+	if key == "PORT" {
+		return "", false, errors.New("remote unavailable")
+	}
+	return "", false, nil
+}
+
+func TestParseContextPassesContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	l := &ctxLookuper{}
+	cfg := Config{}
+	err := env.ParseContext(ctx, &cfg, env.WithLookuper(l))
+	assert.EqualError(t, err, "remote unavailable")
+	assert.Equal(t, "value", l.ctx.Value(ctxKey{}))
+}
+
+func TestParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := Config{}
+	assert.Equal(t, context.Canceled, env.ParseContext(ctx, &cfg))
+}
+
+type slowLookuper struct{}
+
+func (l slowLookuper) Lookup(key string) (string, bool) {
+	return "", false
+}
+
+func (l slowLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if key == "SLOW" {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+	return "value", true, nil
+}
+
+func TestFieldTimeout(t *testing.T) {
+	type config struct {
+		Slow string `env:"SLOW" envTimeout:"10ms"`
+		Fast string `env:"FAST" envTimeout:"1s"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(slowLookuper{}))
+	assert.EqualError(t, err, "Lookup of SLOW timed out after 10ms")
+	assert.Equal(t, "value", cfg.Fast)
+
+	type invalid struct {
+		Slow string `env:"SLOW" envTimeout:"soon"`
+	}
+	assert.EqualError(t, env.Parse(&invalid{}, env.WithLookuper(slowLookuper{})), "Invalid envTimeout soon, expected a positive duration")
+}
+
+func TestParseWithFuncsInner(t *testing.T) {
+	type foo struct {
+		name string
+	}
+	type inner struct {
+		Var foo `env:"VAR"`
+	}
+	type config struct {
+		Inner *inner
+	}
+
+	cfg := &config{Inner: &inner{}}
+	err := env.ParseWithFuncs(cfg, env.CustomParsers{
+		reflect.TypeOf(foo{}): func(v string) (interface{}, error) {
+			return foo{name: v}, nil
+		},
+	}, env.WithLookuper(env.Map{"VAR": "test"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "test", cfg.Inner.Var.name)
+}
+
+func TestParsesDurationsEverywhere(t *testing.T) {
+	type config struct {
+		Ptr       *time.Duration           `env:"PTR"`
+		Slice     []time.Duration          `env:"SLICE"`
+		Map       map[string]time.Duration `env:"MAP"`
+		PtrString *string                  `env:"PTR_STRING"`
+		Ints      map[string]int           `env:"INTS" envSeparator:";" envKeyValSeparator:"="`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"PTR":        "1s",
+		"SLICE":      "1s,2m",
+		"MAP":        "read:1s,write:2s",
+		"PTR_STRING": "value",
+		"INTS":       "a=1;b=2",
+	})))
+	assert.Equal(t, time.Second, *cfg.Ptr)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, cfg.Slice)
+	assert.Equal(t, map[string]time.Duration{"read": time.Second, "write": 2 * time.Second}, cfg.Map)
+	assert.Equal(t, "value", *cfg.PtrString)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, cfg.Ints)
+}
+
+func TestInvalidMap(t *testing.T) {
+	type config struct {
+		Map map[string]time.Duration `env:"MAP"`
+	}
+	cfg := config{}
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"MAP": "read:1s,write"})), "Invalid map item: write")
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"MAP": "read:1x"})))
+}
+
+func TestUnsupportedNestedMap(t *testing.T) {
+	type config struct {
+		Map map[string][]string `env:"MAP"`
+	}
+	cfg := config{}
+	assert.Equal(t, env.ErrUnsupportedType, env.Parse(&cfg, env.WithLookuper(env.Map{"MAP": "a:b"})))
+}
+
+func TestNestedMap(t *testing.T) {
+	type config struct {
+		Headers map[string]map[string]string   `env:"HEADERS"`
+		Limits  map[string]map[string]int      `env:"LIMITS" envSeparator:"|" envKeyValSeparator:"=" envInnerSeparator:"," envOuterKeyValSeparator:"@"`
+		Roles   map[string]map[string]struct{} `env:"ROLES"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"HEADERS": "svcA=k1:v1;k2:v2,svcB=k3:v3",
+		"LIMITS":  "api@read=1,write=2|web@read=3",
+		"ROLES":   "alice=admin;dev,bob=dev",
+	})))
+	assert.Equal(t, map[string]map[string]string{
+		"svcA": {"k1": "v1", "k2": "v2"},
+		"svcB": {"k3": "v3"},
+	}, cfg.Headers)
+	assert.Equal(t, map[string]map[string]int{
+		"api": {"read": 1, "write": 2},
+		"web": {"read": 3},
+	}, cfg.Limits)
+	assert.Equal(t, map[string]map[string]struct{}{
+		"alice": {"admin": {}, "dev": {}},
+		"bob":   {"dev": {}},
+	}, cfg.Roles)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"HEADERS": "svcA=k1:v1;k2"})), "Invalid map item: k2")
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"HEADERS": "svcA:k1:v1"})), "Invalid map item: svcA:k1:v1")
+}
+
+func TestParsesNamedTypes(t *testing.T) {
+	type level int
+	type name string
+	type config struct {
+		Level  level   `env:"LEVEL"`
+		Names  []name  `env:"NAMES"`
+		Levels []level `env:"LEVELS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"LEVEL":  "3",
+		"NAMES":  "a,b",
+		"LEVELS": "1,2",
+	})))
+	assert.Equal(t, level(3), cfg.Level)
+	assert.Equal(t, []name{"a", "b"}, cfg.Names)
+	assert.Equal(t, []level{1, 2}, cfg.Levels)
+}
+
+func TestCustomParserAnyType(t *testing.T) {
+	type foo struct {
+		name string
+	}
+	type config struct {
+		Port  int   `env:"PORT"`
+		Foos  []foo `env:"FOOS"`
+		Other int64 `env:"OTHER"`
+	}
+	cfg := config{}
+	err := env.ParseWithFuncs(&cfg, env.CustomParsers{
+		reflect.TypeOf(0): func(v string) (interface{}, error) {
+			return len(v), nil
+		},
+		reflect.TypeOf(foo{}): func(v string) (interface{}, error) {
+			return foo{name: v}, nil
+		},
+	}, env.WithLookuper(env.Map{"PORT": "abc", "FOOS": "a,b", "OTHER": "10"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.Port)
+	assert.Equal(t, []foo{{name: "a"}, {name: "b"}}, cfg.Foos)
+	assert.Equal(t, int64(10), cfg.Other)
+}
+
+func TestTrim(t *testing.T) {
+	type config struct {
+		Port    int    `env:"PORT,trim"`
+		Enabled bool   `env:"ENABLED,trim"`
+		Name    string `env:"NAME,trim"`
+		Raw     string `env:"RAW"`
+	}
+	lookuper := env.WithLookuper(env.Map{
+		"PORT":    `" 8080 "`,
+		"ENABLED": "'true'",
+		"NAME":    `  "unbalanced' `,
+		"RAW":     " 'raw' ",
+	})
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, lookuper))
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, true, cfg.Enabled)
+	assert.Equal(t, `"unbalanced'`, cfg.Name)
+	assert.Equal(t, " 'raw' ", cfg.Raw)
+
+	cfg = config{}
+	assert.NoError(t, env.Parse(&cfg, lookuper, env.WithTrim()))
+	assert.Equal(t, "raw", cfg.Raw)
+}
+
+func TestRelaxedBool(t *testing.T) {
+	type config struct {
+		Yes      bool   `env:"YES,relaxedBool"`
+		Off      bool   `env:"OFF,relaxedBool"`
+		Enabled  bool   `env:"ENABLED,relaxedBool"`
+		Standard bool   `env:"STANDARD,relaxedBool"`
+		Bools    []bool `env:"BOOLS,relaxedBool"`
+		Strict   bool   `env:"STRICT"`
+	}
+	lookuper := env.WithLookuper(env.Map{
+		"YES":      "Yes",
+		"OFF":      "off",
+		"ENABLED":  "ENABLED",
+		"STANDARD": "t",
+		"BOOLS":    "on,no,1",
+	})
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, lookuper))
+	assert.True(t, cfg.Yes)
+	assert.False(t, cfg.Off)
+	assert.True(t, cfg.Enabled)
+	assert.True(t, cfg.Standard)
+	assert.Equal(t, []bool{true, false, true}, cfg.Bools)
+
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"YES": "maybe"})))
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"STRICT": "yes"})))
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"STRICT": "yes"}), env.WithRelaxedBool()))
+	assert.True(t, cfg.Strict)
+}
+
+func TestIntBase(t *testing.T) {
+	type config struct {
+		Size  int   `env:"SIZE" envBase:"0"`
+		Mask  uint  `env:"MASK" envBase:"0"`
+		Big   int64 `env:"BIG" envBase:"0"`
+		Hex   []int `env:"HEX" envBase:"16"`
+		Plain int   `env:"PLAIN"`
+	}
+	lookuper := env.WithLookuper(env.Map{
+		"SIZE":  "1_000_000",
+		"MASK":  "0o755",
+		"BIG":   "0x1F",
+		"HEX":   "ff,10",
+		"PLAIN": "0x10",
+	})
+	cfg := config{}
+	assert.Error(t, env.Parse(&cfg, lookuper))
+	assert.Equal(t, 1000000, cfg.Size)
+	assert.Equal(t, uint(0755), cfg.Mask)
+	assert.Equal(t, int64(31), cfg.Big)
+	assert.Equal(t, []int{255, 16}, cfg.Hex)
+
+	assert.NoError(t, env.Parse(&cfg, lookuper, env.WithIntBase(0)))
+	assert.Equal(t, 16, cfg.Plain)
+	assert.Equal(t, []int{255, 16}, cfg.Hex)
+}
+
+func TestInvalidIntBase(t *testing.T) {
+	type config struct {
+		Size int `env:"SIZE" envBase:"1"`
+	}
+	cfg := config{}
+	assert.EqualError(t, env.Parse(&cfg), "Invalid envBase 1, expected 0 or 2 to 36")
+}
+
+func TestFileMode(t *testing.T) {
+	type config struct {
+		Mode    os.FileMode  `env:"MODE"`
+		DirMode *os.FileMode `env:"DIR_MODE"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"MODE":     "0644",
+		"DIR_MODE": "0o755",
+	})))
+	assert.Equal(t, os.FileMode(0644), cfg.Mode)
+	assert.Equal(t, os.FileMode(0755), *cfg.DirMode)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"MODE": "0999"})), "Invalid file mode 0999, expected octal permissions from 0 to 0777")
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"MODE": "01777"})))
+}
+
+func TestMailAddress(t *testing.T) {
+	type config struct {
+		From       mail.Address    `env:"FROM"`
+		ReplyTo    *mail.Address   `env:"REPLY_TO"`
+		Recipients []*mail.Address `env:"RECIPIENTS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"FROM":       "Alerts <alerts@example.com>",
+		"REPLY_TO":   "noreply@example.com",
+		"RECIPIENTS": `"Ops, Team" <ops@example.com>, dev@example.com`,
+	})))
+	assert.Equal(t, mail.Address{Name: "Alerts", Address: "alerts@example.com"}, cfg.From)
+	assert.Equal(t, "noreply@example.com", cfg.ReplyTo.Address)
+	assert.Equal(t, []*mail.Address{
+		{Name: "Ops, Team", Address: "ops@example.com"},
+		{Address: "dev@example.com"},
+	}, cfg.Recipients)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"FROM": "not an address"}))
+	assert.EqualError(t, err, "Invalid mail address not an address: mail: no angle-addr")
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"RECIPIENTS": "a@example.com, nope"})))
+}
+
+func TestBigNumbers(t *testing.T) {
+	type config struct {
+		Int   *big.Int   `env:"INT"`
+		Float *big.Float `env:"FLOAT"`
+		Rat   *big.Rat   `env:"RAT"`
+		Ints  []big.Int  `env:"INTS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"INT":   "123456789012345678901234567890",
+		"FLOAT": "0.1",
+		"RAT":   "19.99",
+		"INTS":  "1,123456789012345678901234567890",
+	})))
+	assert.Equal(t, "123456789012345678901234567890", cfg.Int.String())
+	assert.Equal(t, "1", cfg.Ints[0].String())
+	assert.Equal(t, 0, cfg.Ints[1].Cmp(cfg.Int))
+	assert.Equal(t, "0.1", cfg.Float.Text('f', 20)[:3])
+	assert.Equal(t, big.NewRat(1999, 100), cfg.Rat)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"INT": "1.5"})), "Invalid integer 1.5")
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"FLOAT": "abc"})))
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"RAT": "abc"})), "Invalid number abc")
+}
+
+type decimal struct {
+	units, cents int
+}
+
+func TestRegisterParser(t *testing.T) {
+	env.RegisterParser(reflect.TypeOf(decimal{}), func(v string) (interface{}, error) {
+		var d decimal
+		_, err := fmt.Sscanf(v, "%d.%d", &d.units, &d.cents)
+		return d, err
+	})
+	type config struct {
+		Price decimal `env:"PRICE"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"PRICE": "12.34"})))
+	assert.Equal(t, decimal{12, 34}, cfg.Price)
+
+	err := env.ParseWithFuncs(&cfg, env.CustomParsers{
+		reflect.TypeOf(decimal{}): func(v string) (interface{}, error) {
+			return decimal{}, nil
+		},
+	}, env.WithLookuper(env.Map{"PRICE": "12.34"}))
+	assert.NoError(t, err)
+	assert.Equal(t, decimal{}, cfg.Price)
+}
+
+type upperString string
+
+func (s *upperString) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty")
+	}
+	*s = upperString(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	type config struct {
+		IP     net.IP        `env:"IP"`
+		IPs    []net.IP      `env:"IPS"`
+		Upper  upperString   `env:"UPPER"`
+		Uppers []upperString `env:"UPPERS"`
+		Ptr    *upperString  `env:"PTR"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"IP":     "10.0.0.1",
+		"IPS":    "10.0.0.1,::1",
+		"UPPER":  "abc",
+		"UPPERS": "a,b",
+		"PTR":    "ptr",
+	})))
+	assert.Equal(t, net.ParseIP("10.0.0.1"), cfg.IP)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}, cfg.IPs)
+	assert.Equal(t, upperString("ABC"), cfg.Upper)
+	assert.Equal(t, []upperString{"A", "B"}, cfg.Uppers)
+	assert.Equal(t, upperString("PTR"), *cfg.Ptr)
+
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"IP": "300.0.0.1"})))
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"UPPERS": "a,,b"})), "empty")
+}
+
+func TestJSONRawMessage(t *testing.T) {
+	type config struct {
+		Template json.RawMessage   `env:"TEMPLATE"`
+		Options  []json.RawMessage `env:"OPTIONS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"TEMPLATE": `{"text": "a, b"}`,
+		"OPTIONS":  `[{"a": 1}, "b", [1, 2]]`,
+	})))
+	assert.Equal(t, json.RawMessage(`{"text": "a, b"}`), cfg.Template)
+	assert.Equal(t, []json.RawMessage{
+		json.RawMessage(`{"a": 1}`),
+		json.RawMessage(`"b"`),
+		json.RawMessage(`[1, 2]`),
+	}, cfg.Options)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"TEMPLATE": `{"a":`})), `Invalid JSON {"a":`)
+	assert.Error(t, env.Parse(&cfg, env.WithLookuper(env.Map{"OPTIONS": `{"a": 1}`})))
+}
+
+func TestOneOf(t *testing.T) {
+	type config struct {
+		Level  string   `env:"LEVEL" envOneOf:"debug,info,warn"`
+		Levels []string `env:"LEVELS" envOneOf:"debug,info,warn" envSeparator:":"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"LEVEL": "info", "LEVELS": "debug:warn"})))
+	assert.Equal(t, config{Level: "info", Levels: []string{"debug", "warn"}}, cfg)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"LEVEL": "trace", "LEVELS": "debug:trace"})),
+		"Invalid value for LEVEL, expected one of debug, info, warn. Invalid value for LEVELS, expected one of debug, info, warn")
+}
+
+func TestOnError(t *testing.T) {
+	type config struct {
+		Workers int      `env:"WORKERS" envDefault:"4" envOnError:"default"`
+		Retries int      `env:"RETRIES" envDefault:"3" envOnError:"ignore"`
+		Level   string   `env:"LEVEL" envOneOf:"debug,info" envOnError:"default" envDefault:"info"`
+		Hosts   []int    `env:"HOSTS" envOnError:"ignore"`
+		Timeout int      `env:"TIMEOUT" envOnError:"fail"`
+		Names   []string `env:"NAMES"`
+	}
+	r := env.Report{}
+	cfg := config{Retries: 5, Hosts: []int{1}}
+	err := env.Parse(&cfg, env.WithReport(&r), env.WithLookuper(env.Map{
+		"WORKERS": "many",
+		"RETRIES": "x",
+		"LEVEL":   "trace",
+		"HOSTS":   "2,x",
+		"TIMEOUT": "x",
+	}))
+	assert.Equal(t, errors.New(`strconv.ParseInt: parsing "x": invalid syntax`), err)
+	assert.Equal(t, 4, cfg.Workers)
+	assert.Equal(t, 5, cfg.Retries)
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, []int{1}, cfg.Hosts)
+	assert.Equal(t, env.FieldReport{
+		Field:   "Workers",
+		Key:     "WORKERS",
+		Source:  env.SourceDefault,
+		Value:   4,
+		Warning: `Invalid value for WORKERS replaced by the default: strconv.ParseInt: parsing "many": invalid syntax`,
+	}, r.Fields[0])
+	assert.Equal(t, `Invalid value for RETRIES ignored: strconv.ParseInt: parsing "x": invalid syntax`, r.Fields[1].Warning)
+	assert.Equal(t, env.SourceUnset, r.Fields[1].Source)
+}
+
+func TestInvalidOnError(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT" envOnError:"skip"`
+	}
+	err := env.Parse(&config{})
+	assert.Equal(t, errors.New("Invalid envOnError skip, expected ignore, default or fail"), err)
+}
+
+func TestExtendedDuration(t *testing.T) {
+	type config struct {
+		Retention time.Duration   `env:"RETENTION,extendedDuration"`
+		TTLs      []time.Duration `env:"TTLS,extendedDuration"`
+		Timeout   time.Duration   `env:"TIMEOUT"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"RETENTION": "2w1.5d",
+		"TTLS":      "1d,-1h30m,0",
+		"TIMEOUT":   "5s",
+	})))
+	assert.Equal(t, 372*time.Hour, cfg.Retention)
+	assert.Equal(t, []time.Duration{24 * time.Hour, -90 * time.Minute, 0}, cfg.TTLs)
+
+	assert.NoError(t, env.Parse(&cfg, env.WithExtendedDurations(), env.WithLookuper(env.Map{"TIMEOUT": "1d"})))
+	assert.Equal(t, 24*time.Hour, cfg.Timeout)
+
+	for _, v := range []string{"1x", "d", "-", "1.5.5d", "999999999w"} {
+		err := env.Parse(&cfg, env.WithExtendedDurations(), env.WithLookuper(env.Map{"TIMEOUT": v}))
+		assert.Equal(t, errors.New("Invalid duration "+v), err, v)
+	}
+}
+
+func TestTrimItemsAndSkipEmpty(t *testing.T) {
+	type config struct {
+		Hosts  []string       `env:"HOSTS,trimItems,skipEmpty"`
+		Ports  []int          `env:"PORTS,trimItems"`
+		Tags   []string       `env:"TAGS,skipEmpty"`
+		Limits map[string]int `env:"LIMITS,trimItems,skipEmpty"`
+		Levels []string       `env:"LEVELS,trimItems" envOneOf:"debug,info"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"HOSTS":  "a, b, ,c,",
+		"PORTS":  " 80, 443 ",
+		"TAGS":   "x,,y",
+		"LIMITS": "read : 10, , write: 5",
+		"LEVELS": "debug, info",
+	})))
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	assert.Equal(t, map[string]int{"read": 10, "write": 5}, cfg.Limits)
+	assert.Equal(t, []string{"debug", "info"}, cfg.Levels)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"PORTS": "80,,443"}))
+	assert.Error(t, err)
+}
+
+func TestQuotedItems(t *testing.T) {
+	type config struct {
+		Tags   []string          `env:"TAGS,quoted" envSeparator:"|"`
+		Names  []string          `env:"NAMES,quoted"`
+		Labels map[string]string `env:"LABELS,quoted"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"TAGS":   `"a|b"|c`,
+		"NAMES":  `"Doe, John","say ""hi""",x\,y`,
+		"LABELS": `team:"core,infra",env:prod`,
+	})))
+	assert.Equal(t, []string{"a|b", "c"}, cfg.Tags)
+	assert.Equal(t, []string{"Doe, John", `say "hi"`, "x,y"}, cfg.Names)
+	assert.Equal(t, map[string]string{"team": "core,infra", "env": "prod"}, cfg.Labels)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"NAMES": `"a,b`}))
+	assert.Equal(t, errors.New(`Unterminated quote in "a,b`), err)
+}
+
+func TestQuotedPairs(t *testing.T) {
+	type config struct {
+		Routes map[string]string `env:"ROUTES,quoted,trimItems"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"ROUTES": `"/a:b": "http://x:80" , c\:d:"e,f"`,
+	})))
+	assert.Equal(t, map[string]string{"/a:b": "http://x:80", "c:d": "e,f"}, cfg.Routes)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"ROUTES": `"a:b"`}))
+	assert.Equal(t, errors.New(`Invalid map item: "a:b"`), err)
+}
+
+func TestEmptySeparator(t *testing.T) {
+	type config struct {
+		Hosts  []string
+		Labels map[string]string
+	}
+	spec := env.NewSpec().
+		Field("Hosts", "HOSTS", env.Separator("")).
+		Field("Labels", "LABELS", env.Separator(""), env.KeyValSeparator(""))
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithSpec(spec), env.WithLookuper(env.Map{
+		"HOSTS":  "ab,c",
+		"LABELS": "a:1,b:2",
+	})))
+	assert.Equal(t, []string{"ab", "c"}, cfg.Hosts)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, cfg.Labels)
+}
+
+func TestUnescapeNewlines(t *testing.T) {
+	type config struct {
+		Cert   string `env:"CERT,unescapeNewlines"`
+		Key    string `env:"KEY,unescapeNewlines"`
+		Banner string `env:"BANNER,unescapeNewlines" envDefault:"hello\nworld"`
+		Raw    string `env:"RAW"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"CERT": `-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n`,
+		"KEY":  "line1\r\nline2\\r\\nline3",
+		"RAW":  `a\nb`,
+	})))
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n", cfg.Cert)
+	assert.Equal(t, "line1\nline2\nline3", cfg.Key)
+	assert.Equal(t, "hello\nworld", cfg.Banner)
+	assert.Equal(t, `a\nb`, cfg.Raw)
+}
+
+func TestNullValue(t *testing.T) {
+	type config struct {
+		Proxy   string         `env:"PROXY" envDefault:"http://proxy" envNullValue:"none"`
+		Timeout *time.Duration `env:"TIMEOUT" envDefault:"5s" envNullValue:"none"`
+		Hosts   []string       `env:"HOSTS" envDefault:"a,b" envNullValue:"-"`
+		Port    int            `env:"PORT" envDefault:"80" envNullValue:"none"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"PROXY":   "none",
+		"TIMEOUT": "none",
+		"HOSTS":   "-",
+	})))
+	assert.Equal(t, config{Port: 80}, cfg)
+
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"PORT": "none"})))
+	assert.Equal(t, "http://proxy", cfg.Proxy)
+	assert.Equal(t, 5*time.Second, *cfg.Timeout)
+	assert.Equal(t, 0, cfg.Port)
+}
+
+func TestSnapshot(t *testing.T) {
+	type setter string
+	type config struct {
+		A setter `env:"SNAPSHOT_A"`
+		B string `env:"SNAPSHOT_B"`
+	}
+	// parsing A changes B, as a concurrent os.Setenv would
+	funcs := env.CustomParsers{reflect.TypeOf(setter("")): func(v string) (interface{}, error) {
+		os.Setenv("SNAPSHOT_B", "changed")
+		return setter(v), nil
+	}}
+	defer os.Unsetenv("SNAPSHOT_A")
+	defer os.Unsetenv("SNAPSHOT_B")
+
+	os.Setenv("SNAPSHOT_A", "a")
+	os.Setenv("SNAPSHOT_B", "b")
+	cfg := config{}
+	assert.NoError(t, env.ParseWithFuncs(&cfg, funcs, env.WithSnapshot()))
+	assert.Equal(t, "b", cfg.B)
+
+	os.Setenv("SNAPSHOT_B", "b")
+	assert.NoError(t, env.ParseWithFuncs(&cfg, funcs))
+	assert.Equal(t, "changed", cfg.B)
+
+	snapshot := env.Snapshot()
+	os.Setenv("SNAPSHOT_A", "later")
+	assert.Equal(t, "a", snapshot["SNAPSHOT_A"])
+}
+
+func TestSnapshotMalformedEntries(t *testing.T) {
+	if os.Getenv("SNAPSHOT_CHILD") == "1" {
+		fmt.Print(env.Snapshot()["SNAPSHOT_CHILD"])
+		return
+	}
+	// only a parent process can pass entries without `=`
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSnapshotMalformedEntries$")
+	cmd.Env = []string{"MALFORMED", "SNAPSHOT_CHILD=1"}
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "1"), string(out))
+}
+
 func ExampleParse() {
 	type config struct {
 		Home         string `env:"HOME"`
@@ -0,0 +1,178 @@
+// Package envazure provides Lookupers reading Azure Key Vault secrets and
+// App Configuration keys, using their REST APIs so env doesn't depend on the
+// Azure SDK.
+//
+// On Azure, credentials come from the managed identity of the resource:
+//
+//	env.Parse(&cfg, env.WithLookuper(envazure.NewKeyVault("https://myvault.vault.azure.net")))
+package envazure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IdentityEndpoint is the default endpoint of the managed identity tokens
+const IdentityEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// TokenFunc returns an OAuth2 access token for an Azure API
+type TokenFunc func(ctx context.Context) (string, error)
+
+// ManagedIdentity returns a TokenFunc getting tokens for resource, e.g.
+// `https://vault.azure.net`, from the managed identity of the resource the
+// program runs on
+func ManagedIdentity(resource string) TokenFunc {
+	return func(ctx context.Context) (string, error) {
+		q := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		req, err := http.NewRequest(http.MethodGet, IdentityEndpoint+"?"+q.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+		var resp struct {
+			AccessToken string `json:"access_token"`
+		}
+		if _, err := do(ctx, http.DefaultClient, req, &resp); err != nil {
+			return "", fmt.Errorf("Cannot get an access token: %v", err)
+		}
+		return resp.AccessToken, nil
+	}
+}
+
+// KeyVault reads secrets from an Azure Key Vault. As secret names can't
+// contain underscores, keys are mapped to secret names with Name, which
+// replaces `_` with `-` by default.
+type KeyVault struct {
+	VaultURL string
+	Name     func(key string) string
+	Token    TokenFunc
+	Client   *http.Client
+}
+
+// NewKeyVault returns a KeyVault reading the vault at vaultURL, e.g.
+// `https://myvault.vault.azure.net`, with the managed identity
+func NewKeyVault(vaultURL string) *KeyVault {
+	return &KeyVault{
+		VaultURL: strings.TrimSuffix(vaultURL, "/"),
+		Name: func(key string) string {
+			return strings.Replace(key, "_", "-", -1)
+		},
+		Token:  ManagedIdentity("https://vault.azure.net"),
+		Client: http.DefaultClient,
+	}
+}
+
+// Lookup is the same as LookupContext, treating errors as unset values
+func (kv *KeyVault) Lookup(key string) (string, bool) {
+	value, ok, err := kv.LookupContext(context.Background(), key)
+	return value, ok && err == nil
+}
+
+// LookupContext returns the latest version of the secret for key
+func (kv *KeyVault) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	name := kv.Name(key)
+	req, err := authorized(ctx, kv.Token, kv.VaultURL+"/secrets/"+pathEscape(name)+"?api-version=7.4")
+	if err != nil {
+		return "", false, err
+	}
+	var resp struct {
+		Value string `json:"value"`
+	}
+	found, err := do(ctx, kv.Client, req, &resp)
+	if err != nil {
+		return "", false, fmt.Errorf("Cannot read secret %s: %v", name, err)
+	}
+	return resp.Value, found, nil
+}
+
+// AppConfiguration reads key-values from an Azure App Configuration store,
+// with the given label, or without label if Label is empty
+type AppConfiguration struct {
+	Endpoint string
+	Label    string
+	Token    TokenFunc
+	Client   *http.Client
+}
+
+// NewAppConfiguration returns an AppConfiguration reading the store at
+// endpoint, e.g. `https://mystore.azconfig.io`, with the managed identity
+func NewAppConfiguration(endpoint, label string) *AppConfiguration {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	return &AppConfiguration{
+		Endpoint: endpoint,
+		Label:    label,
+		Token:    ManagedIdentity(endpoint),
+		Client:   http.DefaultClient,
+	}
+}
+
+// Lookup is the same as LookupContext, treating errors as unset values
+func (ac *AppConfiguration) Lookup(key string) (string, bool) {
+	value, ok, err := ac.LookupContext(context.Background(), key)
+	return value, ok && err == nil
+}
+
+// LookupContext returns the value of key with the configured label
+func (ac *AppConfiguration) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	q := url.Values{"api-version": {"1.0"}}
+	if ac.Label != "" {
+		q.Set("label", ac.Label)
+	}
+	req, err := authorized(ctx, ac.Token, ac.Endpoint+"/kv/"+pathEscape(key)+"?"+q.Encode())
+	if err != nil {
+		return "", false, err
+	}
+	var resp struct {
+		Value string `json:"value"`
+	}
+	found, err := do(ctx, ac.Client, req, &resp)
+	if err != nil {
+		return "", false, fmt.Errorf("Cannot read key %s: %v", key, err)
+	}
+	return resp.Value, found, nil
+}
+
+func pathEscape(s string) string {
+	return (&url.URL{Path: s}).EscapedPath()
+}
+
+// authorized returns a GET request to u with a token from token
+func authorized(ctx context.Context, token TokenFunc, u string) (*http.Request, error) {
+	t, err := token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+t)
+	return req, nil
+}
+
+// do sends req and decodes its JSON response into v, returning false if
+// the resource was not found
+func do(ctx context.Context, client *http.Client, req *http.Request, v interface{}) (bool, error) {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return false, errors.New(resp.Status + ": " + msg)
+		}
+		return false, errors.New(resp.Status)
+	}
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
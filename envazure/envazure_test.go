@@ -0,0 +1,86 @@
+package envazure_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caarlos0/env/envazure"
+	"github.com/stretchr/testify/assert"
+)
+
+func token(context.Context) (string, error) {
+	return "token", nil
+}
+
+func TestKeyVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "7.4", r.URL.Query().Get("api-version"))
+		switch r.URL.Path {
+		case "/secrets/DB-PASSWORD":
+			fmt.Fprint(w, `{"value": "hunter2", "id": "https://myvault.vault.azure.net/secrets/DB-PASSWORD/1"}`)
+		case "/secrets/BROKEN":
+			http.Error(w, "throttled", http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	kv := envazure.NewKeyVault(server.URL + "/")
+	kv.Token, kv.Client = token, server.Client()
+	value, ok := kv.Lookup("DB_PASSWORD")
+	assert.True(t, ok)
+	assert.Equal(t, "hunter2", value)
+
+	_, ok, err := kv.LookupContext(context.Background(), "API_KEY")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = kv.LookupContext(context.Background(), "BROKEN")
+	assert.EqualError(t, err, "Cannot read secret BROKEN: 429 Too Many Requests: throttled")
+
+	kv.Token = func(context.Context) (string, error) { return "", errors.New("no identity") }
+	_, _, err = kv.LookupContext(context.Background(), "DB_PASSWORD")
+	assert.EqualError(t, err, "no identity")
+}
+
+func TestAppConfiguration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path + "@" + r.URL.Query().Get("label") {
+		case "/kv/LOG_LEVEL@":
+			fmt.Fprint(w, `{"key": "LOG_LEVEL", "value": "info"}`)
+		case "/kv/LOG_LEVEL@production":
+			fmt.Fprint(w, `{"key": "LOG_LEVEL", "label": "production", "value": "warn"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ac := envazure.NewAppConfiguration(server.URL, "")
+	ac.Token, ac.Client = token, server.Client()
+	value, ok := ac.Lookup("LOG_LEVEL")
+	assert.True(t, ok)
+	assert.Equal(t, "info", value)
+
+	ac.Label = "production"
+	value, ok = ac.Lookup("LOG_LEVEL")
+	assert.True(t, ok)
+	assert.Equal(t, "warn", value)
+
+	_, ok, err := ac.LookupContext(context.Background(), "PORT")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+// Package envcmd reads variables from the output of commands, so secret
+// injection tools with a CLI can be used as sources:
+//
+//	vars, err := envcmd.SOPS(ctx, "secrets.enc.env")
+//	// ...
+//	err = env.Parse(&cfg, env.WithLookuper(vars))
+//
+// Tools without a dedicated function can be wrapped with Command, e.g.
+// 1Password's `op inject`, which resolves the secret references of a
+// template:
+//
+//	vars, err := envcmd.Command(ctx, "op", "inject", "-i", "app.env.tpl")
+package envcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/caarlos0/env"
+)
+
+// Command runs name with args and reads its output into an env.Map. The
+// output is either `KEY=value` lines or a JSON object of strings. Unlike
+// `env.ReadDotEnv`, values are taken literally, up to the end of the line:
+// `$` and `#` in secrets are neither expanded nor comments. Blank lines and
+// lines starting with `#` are ignored.
+func Command(ctx context.Context, name string, args ...string) (env.Map, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %v: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %v", name, err)
+	}
+	if out := bytes.TrimSpace(stdout.Bytes()); bytes.HasPrefix(out, []byte("{")) {
+		m := env.Map{}
+		if err := json.Unmarshal(out, &m); err != nil {
+			return nil, fmt.Errorf("%s output: %v", name, err)
+		}
+		return m, nil
+	}
+	return parseLines(stdout.String(), name)
+}
+
+// parseLines reads the literal `KEY=value` lines of the output of name
+func parseLines(out, name string) (env.Map, error) {
+	m := env.Map{}
+	for i, line := range strings.Split(out, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(key), "export "))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s output:%d: expected KEY=value", name, i+1)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// SOPS decrypts the SOPS encrypted file at path with the `sops` command.
+// The file must be flat, e.g. a .env file or a YAML or JSON file without
+// nested keys.
+func SOPS(ctx context.Context, path string) (env.Map, error) {
+	return Command(ctx, "sops", "--decrypt", "--output-type", "dotenv", path)
+}
+
+// Doppler downloads the secrets of the current Doppler project and config
+// with the `doppler` command. args are added to the command, e.g.
+// `--project`, `--config` or `--token`.
+func Doppler(ctx context.Context, args ...string) (env.Map, error) {
+	return Command(ctx, "doppler", append([]string{"secrets", "download", "--no-file", "--format", "json"}, args...)...)
+}
@@ -0,0 +1,68 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package envcmd_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCommand installs a command named name, running script, in a directory
+// put first in PATH for the duration of the test
+func fakeCommand(t *testing.T, name, script string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestCommand(t *testing.T) {
+	vars, err := envcmd.Command(context.Background(), "sh", "-c", "printf 'HOST=localhost\nPORT=8080\n'")
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{"HOST": "localhost", "PORT": "8080"}, vars)
+
+	vars, err = envcmd.Command(context.Background(), "sh", "-c", `echo '{"HOST": "localhost"}'`)
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{"HOST": "localhost"}, vars)
+
+	vars, err = envcmd.Command(context.Background(), "sh", "-c", `printf '# secrets\n\nexport DB_PASSWORD=p@ss$word#1\nTOKEN=x$HOME\r\n'`)
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{"DB_PASSWORD": "p@ss$word#1", "TOKEN": "x$HOME"}, vars)
+
+	_, err = envcmd.Command(context.Background(), "sh", "-c", "printf 'HOST=localhost\nnot a variable\n'")
+	assert.EqualError(t, err, "sh output:2: expected KEY=value")
+
+	_, err = envcmd.Command(context.Background(), "sh", "-c", `echo '{"PORT": 8080}'`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sh output: json: cannot unmarshal number")
+
+	_, err = envcmd.Command(context.Background(), "sh", "-c", "echo 'not logged in' >&2; exit 3")
+	assert.EqualError(t, err, "sh failed: exit status 3: not logged in")
+
+	_, err = envcmd.Command(context.Background(), "sh", "-c", "exit 1")
+	assert.EqualError(t, err, "sh failed: exit status 1")
+}
+
+func TestSOPS(t *testing.T) {
+	fakeCommand(t, "sops", `[ "$*" = "--decrypt --output-type dotenv secrets.enc.env" ] || exit 1
+echo DB_PASSWORD=hunter2`)
+	vars, err := envcmd.SOPS(context.Background(), "secrets.enc.env")
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{"DB_PASSWORD": "hunter2"}, vars)
+}
+
+func TestDoppler(t *testing.T) {
+	fakeCommand(t, "doppler", `[ "$*" = "secrets download --no-file --format json --config prd" ] || exit 1
+echo '{"DB_PASSWORD": "hunter2"}'`)
+	vars, err := envcmd.Doppler(context.Background(), "--config", "prd")
+	assert.NoError(t, err)
+	assert.Equal(t, env.Map{"DB_PASSWORD": "hunter2"}, vars)
+}
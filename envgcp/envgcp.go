@@ -0,0 +1,187 @@
+// Package envgcp provides Lookupers reading Google Cloud Secret Manager
+// secrets and Compute Engine instance metadata, using their REST APIs so env
+// doesn't depend on the Google Cloud client libraries.
+//
+// On GCP, credentials come from the metadata server of the instance:
+//
+//	env.Parse(&cfg, env.WithLookuper(envgcp.NewSecretManager(nil)))
+//
+// resolves variables set to `sm://projects/p/secrets/name` to the latest
+// version of the secret.
+package envgcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/caarlos0/env"
+)
+
+const (
+	// SecretManagerEndpoint is the default endpoint of the Secret Manager API
+	SecretManagerEndpoint = "https://secretmanager.googleapis.com/v1/"
+	// MetadataEndpoint is the default endpoint of the metadata server
+	MetadataEndpoint = "http://metadata.google.internal/computeMetadata/v1/"
+
+	referencePrefix = "sm://"
+)
+
+// TokenFunc returns an OAuth2 access token for the Google Cloud APIs
+type TokenFunc func(ctx context.Context) (string, error)
+
+// SecretManager resolves the values of Base that are references to Secret
+// Manager secrets, of the form `sm://projects/p/secrets/name`, optionally
+// followed by `/versions/v` (the latest version is used by default). Other
+// values are returned as is.
+type SecretManager struct {
+	Base     env.Lookuper
+	Endpoint string
+	Token    TokenFunc
+	Client   *http.Client
+}
+
+// NewSecretManager returns a SecretManager resolving the references in base,
+// or in the process environment if base is nil, with the credentials of the
+// instance's default service account
+func NewSecretManager(base env.Lookuper) *SecretManager {
+	if base == nil {
+		base = env.LookuperFunc(os.LookupEnv)
+	}
+	return &SecretManager{
+		Base:     base,
+		Endpoint: SecretManagerEndpoint,
+		Token:    NewMetadata().Token,
+		Client:   http.DefaultClient,
+	}
+}
+
+// Lookup is the same as LookupContext, treating errors as unset values
+func (s *SecretManager) Lookup(key string) (string, bool) {
+	value, ok, err := s.LookupContext(context.Background(), key)
+	return value, ok && err == nil
+}
+
+// LookupContext returns the value of key in Base, resolving it if it is a
+// reference to a secret
+func (s *SecretManager) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	value, ok := s.Base.Lookup(key)
+	if !ok || !strings.HasPrefix(value, referencePrefix) {
+		return value, ok, nil
+	}
+	name := strings.TrimPrefix(value, referencePrefix)
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := s.Token(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	req, err := http.NewRequest(http.MethodGet, s.Endpoint+name+":access", nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := do(ctx, s.Client, req, &resp); err != nil {
+		return "", false, fmt.Errorf("Cannot access secret %s: %v", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", false, fmt.Errorf("Cannot access secret %s: %v", name, err)
+	}
+	return string(data), true, nil
+}
+
+// Metadata reads the custom metadata attributes of the Compute Engine
+// instance, keyed by attribute name
+type Metadata struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+// NewMetadata returns a Metadata reading the metadata server of the instance
+func NewMetadata() *Metadata {
+	return &Metadata{Endpoint: MetadataEndpoint, Client: http.DefaultClient}
+}
+
+// Lookup is the same as LookupContext, treating errors as unset values
+func (m *Metadata) Lookup(key string) (string, bool) {
+	value, ok, err := m.LookupContext(context.Background(), key)
+	return value, ok && err == nil
+}
+
+// LookupContext returns the instance attribute named key
+func (m *Metadata) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	req, err := m.request("instance/attributes/" + key)
+	if err != nil {
+		return "", false, err
+	}
+	resp, err := m.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", false, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("Cannot read instance attribute %s: %s", key, resp.Status)
+	}
+	return string(body), true, nil
+}
+
+// Token returns an access token of the instance's default service account
+func (m *Metadata) Token(ctx context.Context) (string, error) {
+	req, err := m.request("instance/service-accounts/default/token")
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := do(ctx, m.Client, req, &resp); err != nil {
+		return "", fmt.Errorf("Cannot get an access token: %v", err)
+	}
+	return resp.AccessToken, nil
+}
+
+func (m *Metadata) request(path string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, m.Endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return req, nil
+}
+
+// do sends req and decodes its JSON response into v
+func do(ctx context.Context, client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return errors.New(resp.Status + ": " + msg)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,95 @@
+package envgcp_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envgcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/projects/p/secrets/db/versions/latest:access":
+			fmt.Fprintf(w, `{"payload": {"data": %q}}`, base64.StdEncoding.EncodeToString([]byte("hunter2")))
+		case "/projects/p/secrets/db/versions/2:access":
+			fmt.Fprintf(w, `{"payload": {"data": %q}}`, base64.StdEncoding.EncodeToString([]byte("swordfish")))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sm := &envgcp.SecretManager{
+		Base: env.Map{
+			"PASSWORD": "sm://projects/p/secrets/db",
+			"PINNED":   "sm://projects/p/secrets/db/versions/2",
+			"MISSING":  "sm://projects/p/secrets/missing",
+			"HOST":     "localhost",
+		},
+		Endpoint: server.URL + "/",
+		Token:    func(context.Context) (string, error) { return "token", nil },
+		Client:   server.Client(),
+	}
+	for key, want := range map[string]string{"PASSWORD": "hunter2", "PINNED": "swordfish", "HOST": "localhost"} {
+		value, ok, err := sm.LookupContext(context.Background(), key)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, want, value)
+	}
+
+	_, ok, err := sm.LookupContext(context.Background(), "UNSET")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = sm.LookupContext(context.Background(), "MISSING")
+	assert.EqualError(t, err, "Cannot access secret projects/p/secrets/missing/versions/latest: 404 Not Found: not found")
+	_, ok = sm.Lookup("MISSING")
+	assert.False(t, ok)
+}
+
+func TestMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/instance/attributes/REGION":
+			fmt.Fprint(w, "europe-west1")
+		case "/instance/attributes/BROKEN":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		case "/instance/service-accounts/default/token":
+			fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	m := &envgcp.Metadata{Endpoint: server.URL + "/", Client: server.Client()}
+	value, ok := m.Lookup("REGION")
+	assert.True(t, ok)
+	assert.Equal(t, "europe-west1", value)
+
+	_, ok, err := m.LookupContext(context.Background(), "ZONE")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = m.LookupContext(context.Background(), "BROKEN")
+	assert.EqualError(t, err, "Cannot read instance attribute BROKEN: 500 Internal Server Error")
+
+	token, err := m.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token", token)
+}
@@ -0,0 +1,48 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+)
+
+// WithExportedSecrets includes the variables of fields tagged with the
+// `secret` option in the output of `Environ`
+func WithExportedSecrets() Option {
+	return func(o *options) {
+		o.exportSecrets = true
+	}
+}
+
+// Environ returns the variables read by Parse for v, as `KEY=value` strings
+// sorted by key, e.g. to pass a vetted environment to a child process with
+// `exec.Cmd.Env`. Undeclared variables are left out, as are secrets unless
+// `WithExportedSecrets` is given. Values are passed as is, without
+// decrypting them. v is not modified and parse errors are ignored.
+func Environ(v interface{}, opts ...Option) []string {
+	ptrRef := reflect.ValueOf(v)
+	if ptrRef.Kind() != reflect.Ptr || ptrRef.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var o *options
+	r := &Report{}
+	opts = append(opts, WithReport(r), func(p *options) { o = p })
+	Parse(clone(ptrRef).Interface(), opts...)
+
+	secrets := map[string]bool{}
+	for _, f := range r.Fields {
+		if f.Secret {
+			secrets[f.Field] = true
+		}
+	}
+	var environ []string
+	for key, fieldPath := range o.consumed {
+		if secrets[fieldPath] && !o.exportSecrets {
+			continue
+		}
+		if value, ok, err := o.lookupRaw(key, 0); ok && err == nil {
+			environ = append(environ, key+"="+value)
+		}
+	}
+	sort.Strings(environ)
+	return environ
+}
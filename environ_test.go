@@ -0,0 +1,38 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnviron(t *testing.T) {
+	type config struct {
+		Home      string            `env:"HOME"`
+		Port      int               `env:"PORT" envDefault:"3000"`
+		Password  string            `env:"PASSWORD,secret"`
+		Features  map[string]string `env:"FEATURE_*"`
+		Upstreams []upstream        `env:"UPSTREAM"`
+	}
+	lookuper := env.WithLookuper(env.Map{
+		"HOME":            "/home/user",
+		"PASSWORD":        "hunter2",
+		"FEATURE_SEARCH":  "on",
+		"UPSTREAM_0_HOST": "example.com",
+		"AWS_SECRET":      "undeclared",
+	})
+	cfg := config{}
+	assert.Equal(t, []string{
+		"FEATURE_SEARCH=on",
+		"HOME=/home/user",
+		"UPSTREAM_0_HOST=example.com",
+	}, env.Environ(&cfg, lookuper))
+	assert.Equal(t, []string{
+		"FEATURE_SEARCH=on",
+		"HOME=/home/user",
+		"PASSWORD=hunter2",
+		"UPSTREAM_0_HOST=example.com",
+	}, env.Environ(&cfg, lookuper, env.WithExportedSecrets()))
+	assert.Equal(t, config{}, cfg)
+}
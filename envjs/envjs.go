@@ -0,0 +1,35 @@
+// Package envjs provides Lookupers for Go compiled to WebAssembly
+// (GOOS=js GOARCH=wasm), where there usually is no process environment, so
+// frontend code can load the same config structs as the server:
+//
+//	env.Parse(&cfg, env.WithLookuper(envjs.Global("APP_CONFIG")))
+//
+// reads the variables from the properties of the `APP_CONFIG` JavaScript
+// object, and `envjs.Location()` reads them from the query parameters of the
+// page's URL. On other platforms, they return an empty Map, so code using
+// them still builds.
+package envjs
+
+import (
+	"net/url"
+
+	"github.com/caarlos0/env"
+)
+
+// Query returns the parameters of the URL query string, such as
+// `?PORT=8080&DEBUG=true`, as a Map. Parameters given more than once take
+// their first value.
+func Query(query string) (env.Map, error) {
+	if len(query) > 0 && query[0] == '?' {
+		query = query[1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	m := env.Map{}
+	for k, v := range values {
+		m[k] = v[0]
+	}
+	return m, nil
+}
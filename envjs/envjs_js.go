@@ -0,0 +1,47 @@
+//go:build js && wasm
+// +build js,wasm
+
+package envjs
+
+import (
+	"syscall/js"
+
+	"github.com/caarlos0/env"
+)
+
+// Global returns the properties of the JavaScript global object name, e.g.
+// `globalThis.APP_CONFIG = {PORT: 8080}`, as a Map. Values are converted to
+// strings as by JavaScript's String(); null and undefined properties are
+// left out. The Map is empty if there is no such object.
+func Global(name string) env.Map {
+	m := env.Map{}
+	obj := js.Global().Get(name)
+	if obj.Type() != js.TypeObject {
+		return m
+	}
+	toString := js.Global().Get("String")
+	keys := js.Global().Get("Object").Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		k := keys.Index(i).String()
+		v := obj.Get(k)
+		if v.IsNull() || v.IsUndefined() {
+			continue
+		}
+		m[k] = toString.Invoke(v).String()
+	}
+	return m
+}
+
+// Location returns the query parameters of the page's URL, as a Map. The
+// Map is empty outside of a browser or if the query string is invalid.
+func Location() env.Map {
+	location := js.Global().Get("location")
+	if location.Type() != js.TypeObject {
+		return env.Map{}
+	}
+	m, err := Query(location.Get("search").String())
+	if err != nil {
+		return env.Map{}
+	}
+	return m
+}
@@ -0,0 +1,17 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package envjs
+
+import "github.com/caarlos0/env"
+
+// Global returns an empty Map, as there is no JavaScript global object on
+// this platform
+func Global(name string) env.Map {
+	return env.Map{}
+}
+
+// Location returns an empty Map, as there is no page URL on this platform
+func Location() env.Map {
+	return env.Map{}
+}
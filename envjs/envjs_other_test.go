@@ -0,0 +1,17 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package envjs_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envjs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyOutsideJS(t *testing.T) {
+	assert.Equal(t, env.Map{}, envjs.Global("APP_CONFIG"))
+	assert.Equal(t, env.Map{}, envjs.Location())
+}
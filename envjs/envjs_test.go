@@ -0,0 +1,27 @@
+package envjs_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envjs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	for query, want := range map[string]env.Map{
+		"":                              {},
+		"?":                             {},
+		"?PORT=8080&DEBUG=true":         {"PORT": "8080", "DEBUG": "true"},
+		"PORT=8080":                     {"PORT": "8080"},
+		"?HOSTS=a&HOSTS=b":              {"HOSTS": "a"},
+		"?GREETING=hello%20world&EMPTY": {"GREETING": "hello world", "EMPTY": ""},
+	} {
+		m, err := envjs.Query(query)
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, m, query)
+	}
+
+	_, err := envjs.Query("?PORT=%zz")
+	assert.EqualError(t, err, `invalid URL escape "%zz"`)
+}
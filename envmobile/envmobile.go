@@ -0,0 +1,104 @@
+// Package envmobile provides Lookupers for apps built with gomobile, which
+// can't rely on the process environment on Android and iOS. The host app
+// passes its configuration, e.g. from Android resources or an iOS property
+// list, in a Bundle:
+//
+//	// Kotlin
+//	val bundle = Envmobile.newBundle()
+//	bundle.set("API_URL", BuildConfig.API_URL)
+//	App.start(bundle)
+//
+//	// Go
+//	func Start(bundle *envmobile.Bundle) error {
+//		var cfg Config
+//		return env.Parse(&cfg, env.WithLookuper(bundle))
+//	}
+//
+// so the config structs can be shared between the server and mobile builds.
+// The methods the host app calls, such as Bundle.Set, and Source only use
+// types gomobile bind supports. Bundle.Lookup and Bundle.Keys, which make a
+// Bundle a Lookuper listing its keys, and FromSource are meant for the Go
+// side: gomobile bind skips them.
+package envmobile
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/caarlos0/env"
+)
+
+// Bundle is a Lookuper holding variables set by the host app. It is safe for
+// concurrent use.
+type Bundle struct {
+	mu   sync.RWMutex
+	vars map[string]string
+}
+
+// NewBundle returns an empty Bundle
+func NewBundle() *Bundle {
+	return &Bundle{vars: map[string]string{}}
+}
+
+// Set sets the value of the variable key
+func (b *Bundle) Set(key, value string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.vars[key] = value
+}
+
+// Get returns the value of the variable key, or "" if it isn't set
+func (b *Bundle) Get(key string) string {
+	v, _ := b.Lookup(key)
+	return v
+}
+
+// Has reports whether the variable key is set
+func (b *Bundle) Has(key string) bool {
+	_, ok := b.Lookup(key)
+	return ok
+}
+
+// Unset removes the variable key
+func (b *Bundle) Unset(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.vars, key)
+}
+
+// Lookup returns the value of the variable key, if set
+func (b *Bundle) Lookup(key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	v, ok := b.vars[key]
+	return v, ok
+}
+
+// Keys returns the keys of the variables set, sorted
+func (b *Bundle) Keys() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	keys := make([]string, 0, len(b.vars))
+	for k := range b.vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Source is implemented by the host app, in Java, Kotlin, Objective-C or
+// Swift, to serve variables from its own storage on demand
+type Source interface {
+	Has(key string) bool
+	Get(key string) string
+}
+
+// FromSource returns a Lookuper reading the variables from src
+func FromSource(src Source) env.Lookuper {
+	return env.LookuperFunc(func(key string) (string, bool) {
+		if !src.Has(key) {
+			return "", false
+		}
+		return src.Get(key), true
+	})
+}
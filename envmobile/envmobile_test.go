@@ -0,0 +1,51 @@
+package envmobile_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envmobile"
+	"github.com/stretchr/testify/assert"
+)
+
+type config struct {
+	APIURL  string `env:"API_URL,required"`
+	Retries int    `env:"RETRIES" envDefault:"3"`
+}
+
+func TestBundle(t *testing.T) {
+	b := envmobile.NewBundle()
+	b.Set("API_URL", "https://api.example.com")
+	b.Set("RETRIES", "5")
+	b.Set("DEBUG", "true")
+	b.Unset("DEBUG")
+	assert.True(t, b.Has("API_URL"))
+	assert.False(t, b.Has("DEBUG"))
+	assert.Equal(t, "", b.Get("DEBUG"))
+	assert.Equal(t, []string{"API_URL", "RETRIES"}, b.Keys())
+
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(b)))
+	assert.Equal(t, config{APIURL: "https://api.example.com", Retries: 5}, cfg)
+}
+
+// source is a Source as implemented by a host app
+type source map[string]string
+
+func (s source) Has(key string) bool {
+	_, ok := s[key]
+	return ok
+}
+
+func (s source) Get(key string) string {
+	return s[key]
+}
+
+func TestFromSource(t *testing.T) {
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(envmobile.FromSource(source{"API_URL": "https://api.example.com"}))))
+	assert.Equal(t, config{APIURL: "https://api.example.com", Retries: 3}, cfg)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(envmobile.FromSource(source{}))),
+		"Required environment variable API_URL is not set")
+}
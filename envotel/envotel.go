@@ -0,0 +1,68 @@
+// Package envotel creates OpenTelemetry spans around `env.Parse` and remote
+// lookups. It lives in its own package so the env package doesn't depend on
+// OpenTelemetry.
+package envotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caarlos0/env"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/caarlos0/env"
+
+// WithTracerProvider is an `env.Option` creating spans with a tracer from tp
+func WithTracerProvider(tp trace.TracerProvider) env.Option {
+	return env.WithTracer(Tracer(tp.Tracer(instrumentationName)))
+}
+
+// Tracer adapts an OpenTelemetry tracer to `env.Tracer`
+func Tracer(t trace.Tracer) env.Tracer {
+	return tracer{t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, env.Span) {
+	ctx, s := t.t.Start(ctx, name)
+	return ctx, span{s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttribute(key string, value interface{}) {
+	s.s.SetAttributes(attr(key, value))
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
+
+func attr(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,103 @@
+package envotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// remote is a ContextLookuper failing for the keys of errs
+type remote struct {
+	values env.Map
+	errs   map[string]error
+}
+
+func (r remote) Lookup(key string) (string, bool) {
+	return r.values.Lookup(key)
+}
+
+func (r remote) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if err := r.errs[key]; err != nil {
+		return "", false, err
+	}
+	value, ok := r.values.Lookup(key)
+	return value, ok, nil
+}
+
+// attrs returns the attributes of span as a map
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Token string `env:"TOKEN"`
+	}
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	src := remote{
+		values: env.Map{"HOST": "localhost"},
+		errs:   map[string]error{"TOKEN": errors.New("permission denied")},
+	}
+	err := env.Parse(&config{}, env.WithLookuper(src), envotel.WithTracerProvider(tp))
+	assert.EqualError(t, err, "permission denied")
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	host, token, parse := spans[0], spans[1], spans[2]
+	assert.Equal(t, "env.Lookup", host.Name())
+	assert.Equal(t, "HOST", attrs(host)["env.key"].AsString())
+	assert.Equal(t, codes.Unset, host.Status().Code)
+	assert.Empty(t, host.Events())
+
+	assert.Equal(t, "env.Lookup", token.Name())
+	assert.Equal(t, "TOKEN", attrs(token)["env.key"].AsString())
+	assert.Equal(t, codes.Error, token.Status().Code)
+	assert.Equal(t, "permission denied", token.Status().Description)
+	if assert.Len(t, token.Events(), 1) {
+		assert.Equal(t, "exception", token.Events()[0].Name)
+	}
+	assert.Equal(t, parse.SpanContext().SpanID(), token.Parent().SpanID())
+
+	assert.Equal(t, "env.Parse", parse.Name())
+	assert.Equal(t, int64(1), attrs(parse)["env.fields"].AsInt64())
+	assert.Equal(t, int64(1), attrs(parse)["env.errors"].AsInt64())
+	assert.Equal(t, "envotel_test.remote", attrs(parse)["env.source"].AsString())
+	assert.Equal(t, codes.Error, parse.Status().Code)
+	assert.Equal(t, "github.com/caarlos0/env", parse.InstrumentationScope().Name)
+}
+
+func TestTracer(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+	}
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("app")
+
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"HOST": "localhost"}), env.WithTracer(envotel.Tracer(tracer))))
+	assert.Equal(t, "localhost", cfg.Host)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "env.Parse", spans[0].Name())
+		assert.Equal(t, "app", spans[0].InstrumentationScope().Name)
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, int64(0), attrs(spans[0])["env.errors"].AsInt64())
+	}
+}
@@ -0,0 +1,360 @@
+// Package envtest helps testing code configured with env. EnvironFor is the
+// inverse of env.Parse: it returns the variables that make Parse reproduce a
+// given config, for round-trip tests of config types:
+//
+//	vars, err := envtest.EnvironFor(&want)
+//	// ...
+//	var got Config
+//	err = env.Parse(&got, env.WithLookuper(vars))
+//	// got equals want
+package envtest
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+)
+
+var (
+	durationType     = reflect.TypeOf(time.Duration(0))
+	fileModeType     = reflect.TypeOf(os.FileMode(0))
+	addressType      = reflect.TypeOf(mail.Address{})
+	addressListType  = reflect.TypeOf([]*mail.Address(nil))
+	rawMessageType   = reflect.TypeOf(json.RawMessage(nil))
+	rawMessagesType  = reflect.TypeOf([]json.RawMessage(nil))
+	secretStringType = reflect.TypeOf(env.SecretString{})
+	featuresType     = reflect.TypeOf(env.Features(nil))
+
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// EnvironFor returns the variables that env.Parse reads into cfg, a struct
+// or a pointer to a struct, set so that parsing them gives cfg back. Fields
+// are written as their parsers read them, e.g. durations as `1m30s` and
+// lists joined with their separator. Keys are prefixed by `envPrefix`, but
+// not by options such as WithPrefix or WithDerivedPrefixes.
+//
+// It fails when Parse can't reproduce a field, e.g. when an empty field has
+// a default or is required, or when an item of a list contains the
+// separator and the field doesn't have the `quoted` option.
+func EnvironFor(cfg interface{}) (env.Map, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, env.ErrNotAStructPtr
+	}
+	m := env.Map{}
+	if err := environ(m, v, "", ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// environ adds the variables of the fields of the struct v to m. path is
+// the path of v's fields and prefix is prepended to their keys.
+func environ(m env.Map, v reflect.Value, path, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		field, fieldPath := v.Field(i), path+sf.Name
+		info, err := env.ParseTag(sf)
+		if err != nil {
+			return errors.New("Field " + fieldPath + ": " + err.Error())
+		}
+		if info.Key == "" {
+			if nested, ok := nestedStruct(field); ok {
+				nestedPrefix := prefix
+				if !info.Inline {
+					nestedPrefix += info.Prefix
+				}
+				if err := environ(m, nested, fieldPath+".", nestedPrefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		key := prefix + info.Key
+		if strings.ContainsAny(key, "*?[") {
+			if err := environPattern(m, field, fieldPath, info); err != nil {
+				return err
+			}
+			continue
+		}
+		if isStructList(field.Type()) {
+			if err := environStructs(m, field, fieldPath, key); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := format(field, info)
+		if err != nil {
+			return errors.New("Field " + fieldPath + ": " + err.Error())
+		}
+		if value == "" {
+			if info.Default == "" && !info.Required {
+				continue
+			}
+			if info.NullValue == "" {
+				return errors.New("Field " + fieldPath + " is empty, but " + key + " is required or has a default")
+			}
+			value = info.NullValue
+		}
+		m[key] = value
+	}
+	return nil
+}
+
+// environPattern adds the entries of field, a map captured by the pattern
+// of its key, to m
+func environPattern(m env.Map, field reflect.Value, fieldPath string, info env.TagInfo) error {
+	for _, opt := range info.Options {
+		if opt == "stripPrefix" || opt == "lowercase" || opt == "dotted" {
+			return errors.New("Field " + fieldPath + " has the " + opt + " option, whose keys can't be reversed")
+		}
+	}
+	iter := field.MapRange()
+	for iter.Next() {
+		value, err := format(iter.Value(), info)
+		if err != nil {
+			return errors.New("Field " + fieldPath + ": " + err.Error())
+		}
+		m[iter.Key().String()] = value
+	}
+	return nil
+}
+
+// environStructs adds the fields of the structs in field, a slice or map of
+// structs, to m, prefixed by `KEY_index_` or `KEY_mapkey_`
+func environStructs(m env.Map, field reflect.Value, fieldPath, key string) error {
+	if field.Kind() == reflect.Slice {
+		for i := 0; i < field.Len(); i++ {
+			n := strconv.Itoa(i)
+			if nested, ok := nestedStruct(field.Index(i)); ok {
+				if err := environ(m, nested, fieldPath+"["+n+"].", key+"_"+n+"_"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	iter := field.MapRange()
+	for iter.Next() {
+		k := fmt.Sprint(iter.Key().Interface())
+		if nested, ok := nestedStruct(iter.Value()); ok {
+			if err := environ(m, nested, fieldPath+"["+k+"].", key+"_"+k+"_"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// format writes v, the value of a field described by info, as its parser
+// reads it. Empty values, such as nil pointers, give an empty string. Other
+// types, read by custom parsers, are written with their String method.
+func format(v reflect.Value, info env.TagInfo) (string, error) {
+	t := v.Type()
+	switch t {
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	case fileModeType:
+		return "0" + strconv.FormatUint(v.Uint(), 8), nil
+	case secretStringType:
+		return v.Interface().(env.SecretString).Value(), nil
+	case featuresType:
+		return strings.Join(v.Interface().(env.Features).Names(), ","), nil
+	case moneyType:
+		if m := v.Interface().(env.Money); hasOption(info, "minorUnits") {
+			return strings.TrimSpace(strconv.FormatInt(m.Amount, 10) + " " + m.Currency), nil
+		}
+	case addressType:
+		addr := v.Interface().(mail.Address)
+		return addr.String(), nil
+	case addressListType:
+		var items []string
+		for _, addr := range v.Interface().([]*mail.Address) {
+			items = append(items, addr.String())
+		}
+		return strings.Join(items, ", "), nil
+	case rawMessageType:
+		return string(v.Bytes()), nil
+	case rawMessagesType:
+		b, err := json.Marshal(v.Interface())
+		return string(b), err
+	}
+	if text, ok, err := marshalText(v); ok {
+		return text, err
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return format(v.Elem(), info)
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), base(info)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), base(info)), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, t.Bits()), nil
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			item, err := format(v.Index(i), info)
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return join(items, info)
+	case reflect.Map:
+		return formatMap(v, info)
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return "", errors.New("Type " + t.String() + " can't be written as a variable")
+}
+
+// formatMap writes v, a map, as `key:value` pairs sorted by key, or as a
+// list of keys for sets. Maps of maps are written as `key=k1:v1;k2:v2`.
+func formatMap(v reflect.Value, info env.TagInfo) (string, error) {
+	set := v.Type().Elem().Kind() == reflect.Struct && v.Type().Elem().NumField() == 0
+	pairInfo, elemInfo := info, info
+	if v.Type().Elem().Kind() == reflect.Map {
+		pairInfo.KeyValSeparator = info.OuterKeyValSeparator
+		elemInfo.Separator = info.InnerSeparator
+	}
+	var items []string
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := format(iter.Key(), info)
+		if err != nil {
+			return "", err
+		}
+		if set {
+			items = append(items, quote(k, info))
+			continue
+		}
+		value, err := format(iter.Value(), elemInfo)
+		if err != nil {
+			return "", err
+		}
+		sep := pairInfo.KeyValSeparator
+		if strings.Contains(k, sep) && !hasOption(info, "quoted") {
+			return "", errors.New("Key " + k + " contains the separator " + sep + ", which requires the quoted option")
+		}
+		items = append(items, quote(k, pairInfo)+sep+quote(value, pairInfo))
+	}
+	sort.Strings(items)
+	return join(items, info)
+}
+
+// join joins the items of a list with the separator of the field, failing
+// when an item contains it unless the field is quoted
+func join(items []string, info env.TagInfo) (string, error) {
+	for i, item := range items {
+		if hasOption(info, "quoted") {
+			items[i] = quote(item, info)
+			continue
+		}
+		if strings.Contains(item, info.Separator) {
+			return "", errors.New("Item " + item + " contains the separator " + info.Separator + ", which requires the quoted option")
+		}
+	}
+	return strings.Join(items, info.Separator), nil
+}
+
+// quote double-quotes s if the field is quoted and s contains a character
+// that would be read differently
+func quote(s string, info env.TagInfo) string {
+	if !hasOption(info, "quoted") || !strings.ContainsAny(s, info.Separator+info.KeyValSeparator+`"\`) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`"`, `""`, `\`, `\\`).Replace(s) + `"`
+}
+
+// marshalText writes v with its MarshalText method, if it has one
+func marshalText(v reflect.Value) (string, bool, error) {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	m, ok := ptr.Interface().(encoding.TextMarshaler)
+	if !ok || v.Kind() == reflect.Ptr {
+		return "", false, nil
+	}
+	text, err := m.MarshalText()
+	return string(text), true, err
+}
+
+// nestedStruct returns the struct in v, a struct or a non-nil pointer to a
+// struct, whose fields are parsed one by one
+func nestedStruct(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct && !isValue(v.Type())
+}
+
+// isStructList reports whether t is a slice or map of structs, read from
+// indexed or keyed variables
+func isStructList(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && elem.NumField() > 0 && !isValue(elem)
+}
+
+// isValue reports whether t, a struct type, is parsed from a single value
+func isValue(t reflect.Type) bool {
+	switch t {
+	case addressType, secretStringType:
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// base returns the base integers are written in
+func base(info env.TagInfo) int {
+	if info.Base < 2 {
+		return 10
+	}
+	return info.Base
+}
+
+func hasOption(info env.TagInfo, opt string) bool {
+	for _, o := range info.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,440 @@
+package envtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"net/mail"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing/quick"
+	"time"
+
+	"github.com/caarlos0/env"
+)
+
+var (
+	generatorType   = reflect.TypeOf((*quick.Generator)(nil)).Elem()
+	bigIntType      = reflect.TypeOf(big.Int{})
+	bigFloatType    = reflect.TypeOf(big.Float{})
+	bigRatType      = reflect.TypeOf(big.Rat{})
+	percentType     = reflect.TypeOf(env.Percent(0))
+	languageTagType = reflect.TypeOf(env.LanguageTag(""))
+	moneyType       = reflect.TypeOf(env.Money{})
+)
+
+// letters are the characters of generated strings, which are never quoted
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// languageTags are the generated LanguageTags, written as Parse normalizes
+// them
+var languageTags = []string{"en", "en-US", "pt-BR", "zh-Hant-TW", "sr-Latn-RS", "de-CH-1996", "es-419", "x-private"}
+
+// currencies are the currencies of generated Money, with 2, 0 and 3 decimals
+var currencies = []string{"EUR", "USD", "JPY", "KWD"}
+
+// RoundTrip checks that cfg, a struct or a pointer to a struct, is parsed
+// back from the variables returned by EnvironFor. The error names the first
+// field that differs, e.g. because its parser doesn't read what its
+// MarshalText method writes. As Parse only fills the nested structs behind
+// non-nil pointers, those of cfg are allocated before parsing.
+func RoundTrip(cfg interface{}, opts ...env.Option) error {
+	vars, err := EnvironFor(cfg)
+	if err != nil {
+		return err
+	}
+	got := reflect.New(reflect.Indirect(reflect.ValueOf(cfg)).Type())
+	allocate(got.Elem(), reflect.Indirect(reflect.ValueOf(cfg)))
+	if err := env.Parse(got.Interface(), append(opts, env.WithLookuper(vars))...); err != nil {
+		return errors.New("Parsing " + fmt.Sprint(vars) + ": " + err.Error())
+	}
+	if changes := env.Diff(cfg, got.Interface()); len(changes) > 0 {
+		c := changes[0]
+		return fmt.Errorf("Field %s didn't survive a round trip: parsed %v, expected %v", c.Field, c.New, c.Old)
+	}
+	if !reflect.DeepEqual(reflect.Indirect(reflect.ValueOf(cfg)).Interface(), got.Elem().Interface()) {
+		return errors.New("Config didn't survive a round trip through " + fmt.Sprint(vars))
+	}
+	return nil
+}
+
+// allocate allocates the nested struct pointers of dst that are set in src,
+// two structs of the same type
+func allocate(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.Struct && dst.Field(i).CanSet() {
+			dst.Field(i).Set(reflect.New(field.Type().Elem()))
+			allocate(dst.Field(i).Elem(), field.Elem())
+		} else if field.Kind() == reflect.Struct && dst.Field(i).CanSet() {
+			allocate(dst.Field(i), field)
+		}
+	}
+}
+
+// Check runs RoundTrip on random configs of the type of cfg, a struct or a
+// pointer to a struct, generated by Value. c sets the number of configs and
+// the source of randomness as it does for quick.Check, and may be nil.
+//
+//	if err := envtest.Check(&Config{}, nil); err != nil {
+//		t.Error(err)
+//	}
+func Check(cfg interface{}, c *quick.Config, opts ...env.Option) error {
+	t := reflect.Indirect(reflect.ValueOf(cfg)).Type()
+	if c == nil {
+		c = &quick.Config{}
+	}
+	r := c.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	count := c.MaxCount
+	if count == 0 {
+		scale := c.MaxCountScale
+		if scale == 0 {
+			scale = 1
+		}
+		count = int(100 * scale)
+	}
+	for i := 0; i < count; i++ {
+		v, err := Value(t, r)
+		if err != nil {
+			return err
+		}
+		if err := RoundTrip(v.Addr().Interface(), opts...); err != nil {
+			return errors.New("#" + strconv.Itoa(i+1) + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// Values returns a generator of random configs of the type of cfg, a struct
+// or a pointer to a struct, for quick.Config's Values:
+//
+//	f := func(cfg *Config) bool { return envtest.RoundTrip(cfg) == nil }
+//	err := quick.Check(f, &quick.Config{Values: envtest.Values(&Config{})})
+//
+// It panics if the configs can't be generated, see Value.
+func Values(cfg interface{}) func([]reflect.Value, *rand.Rand) {
+	t := reflect.TypeOf(cfg)
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	return func(args []reflect.Value, r *rand.Rand) {
+		for i := range args {
+			v, err := Value(st, r)
+			if err != nil {
+				panic(err)
+			}
+			if t.Kind() == reflect.Ptr {
+				v = v.Addr()
+			}
+			args[i] = v
+		}
+	}
+}
+
+// Value returns a random config of t, a struct type, that EnvironFor can
+// write. Fields are generated within the bounds of their tags, such as
+// `envOneOf`, `envClamp` and `envMinDuration`; fields that are required or
+// have a default are never empty. Types implementing quick.Generator
+// generate their own values, other types without a built-in parser, and
+// kinds Parse doesn't support such as int8, can't be generated. Unexported
+// fields, lists of structs and maps captured by a pattern are left empty.
+func Value(t reflect.Type, r *rand.Rand) (reflect.Value, error) {
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, env.ErrNotAStructPtr
+	}
+	v := reflect.New(t).Elem()
+	if err := generateStruct(v, "", r); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// generateStruct sets the fields of the struct v to random values. path is
+// the path of v's fields.
+func generateStruct(v reflect.Value, path string, r *rand.Rand) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		field, fieldPath := v.Field(i), path+sf.Name
+		info, err := env.ParseTag(sf)
+		if err != nil {
+			return errors.New("Field " + fieldPath + ": " + err.Error())
+		}
+		if info.Key == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct || isValue(ft) {
+				continue
+			}
+			nested := reflect.New(ft)
+			if err := generateStruct(nested.Elem(), fieldPath+".", r); err != nil {
+				return err
+			}
+			if sf.Type.Kind() == reflect.Ptr {
+				field.Set(nested)
+			} else {
+				field.Set(nested.Elem())
+			}
+			continue
+		}
+		if strings.ContainsAny(info.Key, "*?[") || isStructList(sf.Type) {
+			continue
+		}
+		value, err := generate(sf.Type, info, r, info.Required || info.Default != "")
+		if err != nil {
+			return errors.New("Field " + fieldPath + ": " + err.Error())
+		}
+		field.Set(value)
+	}
+	return nil
+}
+
+// generate returns a random value of t for a field described by info.
+// Values are never empty if nonEmpty is set.
+func generate(t reflect.Type, info env.TagInfo, r *rand.Rand, nonEmpty bool) (reflect.Value, error) {
+	if len(info.OneOf) > 0 {
+		return env.Convert(info.OneOf[r.Intn(len(info.OneOf))], t)
+	}
+	if t.Implements(generatorType) {
+		v, _ := quick.Value(t, r)
+		return v, nil
+	}
+	switch t {
+	case durationType:
+		lo, hi := bounds(info.MinDuration, info.MaxDuration)
+		return reflect.ValueOf(lo + time.Duration(r.Int63n(int64(hi-lo)+1))), nil
+	case fileModeType:
+		return reflect.ValueOf(os.FileMode(r.Intn(01000))), nil
+	case secretStringType:
+		return reflect.ValueOf(env.NewSecretString(randomString(r))), nil
+	case addressType:
+		return reflect.ValueOf(randomAddress(r)), nil
+	case addressListType:
+		var list []*mail.Address
+		for n := length(r, nonEmpty); len(list) < n; {
+			addr := randomAddress(r)
+			list = append(list, &addr)
+		}
+		return reflect.ValueOf(list), nil
+	case rawMessageType:
+		return reflect.ValueOf([]byte(strconv.Itoa(r.Intn(1000)))).Convert(t), nil
+	case bigIntType:
+		return reflect.ValueOf(*big.NewInt(r.Int63() - r.Int63())), nil
+	case bigFloatType:
+		// parsed, as the precision of parsed big.Floats is higher
+		text, _ := big.NewFloat(r.NormFloat64() * 1e6).MarshalText()
+		return env.Convert(string(text), t)
+	case bigRatType:
+		text, _ := big.NewRat(r.Int63n(2e6)-1e6, 1+r.Int63n(1000)).MarshalText()
+		return env.Convert(string(text), t)
+	case percentType:
+		// parsed, as percentages are divided by 100
+		return env.Convert(strconv.FormatFloat(float64(r.Intn(10001))/100, 'f', -1, 64)+"%", t)
+	case languageTagType:
+		return reflect.ValueOf(env.LanguageTag(languageTags[r.Intn(len(languageTags))])), nil
+	case moneyType:
+		currency := info.Currency
+		if currency == "" {
+			currency = currencies[r.Intn(len(currencies))]
+		}
+		return reflect.ValueOf(env.Money{Amount: r.Int63n(2e12) - 1e12, Currency: currency}), nil
+	case featuresType:
+		// only enabled flags are listed, and Parse never leaves the set nil
+		features := env.Features{}
+		for n := length(r, nonEmpty); len(features) < n; {
+			features[strings.ToLower(randomString(r))] = true
+		}
+		return reflect.ValueOf(features), nil
+	}
+	if isGeneric(t, "Range") {
+		return randomRange(t, r)
+	}
+	if isGeneric(t, "Weighted") {
+		value, err := generate(t.Field(0).Type, env.TagInfo{}, r, true)
+		if err != nil {
+			return value, err
+		}
+		v := reflect.New(t).Elem()
+		v.Field(0).Set(value)
+		v.Field(1).SetInt(int64(r.Intn(10)))
+		return v, nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Ptr:
+		if !nonEmpty && r.Intn(4) == 0 {
+			return v, nil
+		}
+		elem, err := generate(t.Elem(), info, r, true)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(elem)
+	case reflect.String:
+		v.SetString(randomString(r))
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Float32, reflect.Float64:
+		return randomNumber(t, info, r)
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Parse only reads these kinds with UnmarshalText
+		if !reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			return v, errors.New("Type " + t.String() + " can't be generated, as Parse doesn't support its kind")
+		}
+		return randomNumber(t, info, r)
+	case reflect.Slice:
+		n := length(r, nonEmpty)
+		for i := 0; i < n; i++ {
+			elem, err := generate(t.Elem(), info, r, true)
+			if err != nil {
+				return v, err
+			}
+			v = reflect.Append(v, elem)
+		}
+	case reflect.Map:
+		n := length(r, nonEmpty)
+		if n > 0 {
+			v.Set(reflect.MakeMap(t))
+		}
+		for i := 0; i < n; i++ {
+			k, err := generate(t.Key(), info, r, true)
+			if err != nil {
+				return v, err
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if t.Elem().Kind() != reflect.Struct || t.Elem().NumField() > 0 {
+				if elem, err = generate(t.Elem(), info, r, true); err != nil {
+					return v, err
+				}
+			}
+			v.SetMapIndex(k, elem)
+		}
+	default:
+		return v, errors.New("Type " + t.String() + " can't be generated, as it doesn't implement quick.Generator")
+	}
+	return v, nil
+}
+
+// randomRange returns a random Range of t, an instantiation of env.Range
+func randomRange(t reflect.Type, r *rand.Rand) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	for i := 0; i < 2; i++ {
+		bound, err := generate(t.Field(i).Type, env.TagInfo{}, r, true)
+		if err != nil {
+			return v, err
+		}
+		v.Field(i).Set(bound)
+	}
+	if less(v.Field(1), v.Field(0)) {
+		lo := v.Field(1).Interface()
+		v.Field(1).Set(v.Field(0))
+		v.Field(0).Set(reflect.ValueOf(lo))
+	}
+	return v, nil
+}
+
+// less reports whether the number a is less than b, of the same type
+func less(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	}
+	return a.Int() < b.Int()
+}
+
+// isGeneric reports whether t is an instantiation of the generic type name
+// of env, such as Range
+func isGeneric(t reflect.Type, name string) bool {
+	return t.PkgPath() == moneyType.PkgPath() && strings.HasPrefix(t.Name(), name+"[")
+}
+
+// randomNumber returns a random number of t within `envClamp`, or within
+// the range parsed for t
+func randomNumber(t reflect.Type, info env.TagInfo, r *rand.Rand) (reflect.Value, error) {
+	lo, hi := -1e6, 1e6
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		// ints are parsed as 32-bit integers
+		lo, hi = math.MinInt32, math.MaxInt32
+		if t.Bits() < 32 {
+			lo, hi = -math.Pow(2, float64(t.Bits()-1)), math.Pow(2, float64(t.Bits()-1))-1
+		}
+	case reflect.Int64:
+		lo, hi = math.MinInt64/2, math.MaxInt64/2
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lo, hi = 0, math.Min(math.MaxUint32, math.Pow(2, float64(t.Bits()))-1)
+	}
+	for _, b := range []struct {
+		value string
+		bound *float64
+	}{{info.ClampMin, &lo}, {info.ClampMax, &hi}} {
+		if b.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(b.value, 64)
+		if err != nil {
+			return reflect.Value{}, errors.New("Invalid envClamp bound " + b.value)
+		}
+		*b.bound = f
+	}
+	x := lo + r.Float64()*(hi-lo)
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(x).Convert(t), nil
+	case reflect.Int64:
+		return reflect.ValueOf(int64(x)).Convert(t), nil
+	}
+	return reflect.ValueOf(math.Round(x)).Convert(t), nil
+}
+
+// bounds returns the range of generated durations, a day long unless set
+// by `envMinDuration` and `envMaxDuration`
+func bounds(min, max *time.Duration) (time.Duration, time.Duration) {
+	switch {
+	case min != nil && max != nil:
+		return *min, *max
+	case min != nil:
+		return *min, *min + 24*time.Hour
+	case max != nil:
+		return *max - 24*time.Hour, *max
+	}
+	return -12 * time.Hour, 12 * time.Hour
+}
+
+// length returns the random length of a list, never zero if nonEmpty is set
+func length(r *rand.Rand, nonEmpty bool) int {
+	if nonEmpty {
+		return 1 + r.Intn(3)
+	}
+	return r.Intn(4)
+}
+
+func randomString(r *rand.Rand) string {
+	b := make([]byte, 1+r.Intn(10))
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func randomAddress(r *rand.Rand) mail.Address {
+	return mail.Address{Address: randomString(r) + "@" + randomString(r) + ".example"}
+}
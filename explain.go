@@ -0,0 +1,218 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// explanation records the field being explained while parsing, and the
+// lookup of its variable, if it was read
+type explanation struct {
+	path  string
+	info  tagInfo
+	field reflect.Value
+
+	read   bool
+	value  string
+	source string
+	err    error
+}
+
+// explainLookup records the lookup of the variable of the field being
+// parsed, if it is the one explained
+func (o *options) explainLookup(value, source string, err error) {
+	if o.explain == nil || o.explain.path != o.currentPath {
+		return
+	}
+	e := o.explain
+	e.read, e.value, e.source, e.err = true, value, source, err
+}
+
+// Explain describes how Parse resolves the field at path, such as
+// `Database.Host`, in v: the variable read, the source consulted and what it
+// returned, the default applied, the parser used and the resulting value.
+// Secret values are redacted. v is not modified.
+func Explain(v interface{}, path string, opts ...Option) (string, error) {
+	ptrRef := reflect.ValueOf(v)
+	if ptrRef.Kind() != reflect.Ptr || ptrRef.Elem().Kind() != reflect.Struct {
+		return "", ErrNotAStructPtr
+	}
+	e := &explanation{path: path}
+	opts = append(opts, WithFields(path), func(o *options) { o.explain = e })
+	parseErr := Parse(clone(ptrRef).Interface(), opts...)
+	if !e.field.IsValid() {
+		return "", errors.New("Field " + path + " not found")
+	}
+	o := newOptions(context.Background(), opts)
+	info := e.info
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s (%s)\n", path, e.field.Type())
+	if info.key == "" {
+		fmt.Fprintf(&buf, "  key: none, the default is always used\n")
+	} else {
+		fmt.Fprintf(&buf, "  key: %s\n", info.key)
+	}
+	source := fmt.Sprintf("%T", o.lookuper)
+	switch {
+	case info.key == "":
+	case isWildcard(info.key):
+		fmt.Fprintf(&buf, "  source: %s, variables matching %s\n", source, info.key)
+	case isStructSlice(e.field.Type(), o.converters), isStructMap(e.field.Type(), o.converters):
+		fmt.Fprintf(&buf, "  source: %s, variables starting with %s_\n", source, info.key)
+	case !e.read:
+		fmt.Fprintf(&buf, "  source: %s, not read by Parse\n", source)
+	default:
+		// the lookup recorded while parsing, so that sources are not queried
+		// twice
+		if e.source != SourceEnvironment && e.source != SourceDefault && e.source != SourceUnset {
+			source = e.source
+		}
+		value := e.value
+		switch {
+		case e.err != nil:
+			fmt.Fprintf(&buf, "  source: %s, failed: %v\n", source, e.err)
+		case e.source == SourceDefault || e.source == SourceUnset:
+			fmt.Fprintf(&buf, "  source: %s, not set\n", source)
+		default:
+			if info.secret {
+				value = redacted
+			}
+			fmt.Fprintf(&buf, "  source: %s, set to %q\n", source, value)
+		}
+	}
+	if info.defaultValue != "" {
+		fmt.Fprintf(&buf, "  default: %q\n", info.defaultValue)
+	}
+	if info.required {
+		fmt.Fprintf(&buf, "  required\n")
+	}
+	fmt.Fprintf(&buf, "  parser: %s\n", o.parserName(e.field.Type(), o.conversion(info)))
+	if parseErr != nil {
+		fmt.Fprintf(&buf, "  error: %v\n", parseErr)
+	} else if info.secret {
+		fmt.Fprintf(&buf, "  value: %s\n", redacted)
+	} else {
+		fmt.Fprintf(&buf, "  value: %#v\n", e.field.Interface())
+	}
+	return buf.String(), nil
+}
+
+// parserName describes how convert parses values of type t
+func (o *options) parserName(t reflect.Type, c *conversion) string {
+	if _, ok := o.funcMap[t]; ok {
+		return "custom parser for " + t.String()
+	}
+	registryMu.RLock()
+	_, registered := registry[t]
+	registryMu.RUnlock()
+	if registered {
+		return "registered parser for " + t.String()
+	}
+	if t == durationType && c.extendedDuration {
+		return "extended duration"
+	}
+	if t == moneyType && c.minorUnits {
+		return "money in minor units"
+	}
+	if _, ok := c.convs[t]; ok {
+		return "built-in parser for " + t.String()
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return t.String() + ".UnmarshalText"
+	}
+	switch {
+	case isStructSlice(t, c.convs):
+		return "indexed variables"
+	case isStructMap(t, c.convs):
+		return "keyed variables"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "pointer to " + o.parserName(t.Elem(), c)
+	case reflect.Slice:
+		return fmt.Sprintf("list separated by %q of %s", c.separator, o.parserName(t.Elem(), c))
+	case reflect.Map:
+		if isSet(t) {
+			return fmt.Sprintf("set separated by %q of %s", c.separator, o.parserName(t.Key(), c))
+		}
+		if isNestedMap(t, c.convs) {
+			inner := *c
+			inner.separator = c.innerSeparator
+			return fmt.Sprintf("map separated by %q and %q of %s to %s", c.separator, c.outerKeyValSeparator,
+				o.parserName(t.Key(), c), o.parserName(t.Elem(), &inner))
+		}
+		return fmt.Sprintf("map separated by %q and %q of %s to %s", c.separator, c.keyValSeparator,
+			o.parserName(t.Key(), c), o.parserName(t.Elem(), c))
+	case reflect.Bool:
+		if c.relaxedBool {
+			return "relaxed bool"
+		}
+	case reflect.Int, reflect.Int64, reflect.Uint:
+		if c.base != 10 {
+			return fmt.Sprintf("%s in base %d", t.Kind(), c.base)
+		}
+	}
+	if _, ok := kindConverters[t.Kind()]; ok {
+		return "built-in parser for " + t.Kind().String()
+	}
+	return "none, the type is not supported"
+}
+
+// clone returns a copy of ptr, a pointer to a struct, that Parse can modify
+// without changing ptr: the nested structs Parse recurses into are copied
+// too, the other fields are replaced rather than modified by Parse. Locks
+// are not copied, the copy gets unlocked ones.
+func clone(ptr reflect.Value) reflect.Value {
+	return clonePtr(ptr, map[uintptr]reflect.Value{})
+}
+
+// clonePtr copies the struct ptr points to, unless it was already copied as
+// part of a cycle
+func clonePtr(ptr reflect.Value, clones map[uintptr]reflect.Value) reflect.Value {
+	if c, ok := clones[ptr.Pointer()]; ok {
+		return c
+	}
+	c := reflect.New(ptr.Type().Elem())
+	clones[ptr.Pointer()] = c
+	cloneFields(c.Elem(), ptr.Elem(), clones)
+	return c
+}
+
+// cloneFields copies the fields of src to dst, structs of the same type,
+// copying nested structs rather than sharing them
+func cloneFields(dst, src reflect.Value, clones map[uintptr]reflect.Value) {
+	if !hasLock(dst.Type()) {
+		dst.Set(src)
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		switch {
+		case !field.CanSet() || syncTypes[field.Type()]:
+		case field.Kind() == reflect.Ptr && !src.Field(i).IsNil() && field.Type().Elem().Kind() == reflect.Struct:
+			field.Set(clonePtr(src.Field(i), clones))
+		case field.Kind() == reflect.Struct:
+			cloneFields(field, src.Field(i), clones)
+		default:
+			field.Set(src.Field(i))
+		}
+	}
+}
+
+// hasLock reports whether t, a struct, holds a sync primitive, which must
+// not be copied
+func hasLock(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		for ft.Kind() == reflect.Array {
+			ft = ft.Elem()
+		}
+		if syncTypes[ft] || ft.Kind() == reflect.Struct && hasLock(ft) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package env_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	type database struct {
+		Host     string `env:"DB_HOST" envDefault:"localhost"`
+		Password string `env:"DB_PASSWORD,secret,required"`
+		Ports    []int  `env:"DB_PORTS" envSeparator:":"`
+	}
+	type config struct {
+		Database *database
+	}
+	cfg := config{Database: &database{}}
+	lookuper := env.WithLookuper(env.Map{"DB_PASSWORD": "hunter2", "DB_PORTS": "1:x"})
+
+	explanation, err := env.Explain(&cfg, "Database.Host", lookuper)
+	assert.NoError(t, err)
+	assert.Equal(t, `Database.Host (string)
+  key: DB_HOST
+  source: env.Map, not set
+  default: "localhost"
+  parser: built-in parser for string
+  value: "localhost"
+`, explanation)
+	assert.Equal(t, "", cfg.Database.Host)
+
+	explanation, err = env.Explain(&cfg, "Database.Password", lookuper)
+	assert.NoError(t, err)
+	assert.Equal(t, `Database.Password (string)
+  key: DB_PASSWORD
+  source: env.Map, set to "[REDACTED]"
+  required
+  parser: built-in parser for string
+  value: [REDACTED]
+`, explanation)
+
+	explanation, err = env.Explain(&cfg, "Database.Ports", lookuper)
+	assert.NoError(t, err)
+	assert.Equal(t, `Database.Ports ([]int)
+  key: DB_PORTS
+  source: env.Map, set to "1:x"
+  parser: list separated by ":" of built-in parser for int
+  error: strconv.ParseInt: parsing "x": invalid syntax
+`, explanation)
+
+	_, err = env.Explain(&cfg, "Database.User", lookuper)
+	assert.EqualError(t, err, "Field Database.User not found")
+}
+
+func TestExplainLooksUpOnce(t *testing.T) {
+	type config struct {
+		mu   sync.Mutex
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	cfg := config{Host: "localhost"}
+	lookuper := &countingLookuper{Map: env.Map{"HOST": "example.com"}}
+
+	explanation, err := env.Explain(&cfg, "Host", env.WithLookuper(lookuper))
+	assert.NoError(t, err)
+	assert.Equal(t, `Host (string)
+  key: HOST
+  source: *env_test.countingLookuper, set to "example.com"
+  parser: built-in parser for string
+  value: "example.com"
+`, explanation)
+	assert.Equal(t, 1, lookuper.calls)
+	assert.Equal(t, "localhost", cfg.Host)
+}
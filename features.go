@@ -0,0 +1,112 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Features is a set of feature flags, read from a list of enabled flags,
+// e.g. `FEATURES=search,beta-ui`, and from per-flag overrides, e.g.
+// `FEATURE_SEARCH=false` or `FEATURE_NEW_CHECKOUT=true`, which take
+// precedence. The prefix of the overrides is set by `envFeaturePrefix` and
+// defaults to `FEATURE_`; overrides of flags missing from the list are only
+// found with a Lookuper that lists its keys. Flag names are case insensitive
+// and `-` in names matches `_` in keys.
+type Features map[string]bool
+
+var featuresType = reflect.TypeOf(Features(nil))
+
+// Enabled reports whether the flag is enabled
+func (f Features) Enabled(name string) bool {
+	return f[featureName(name)]
+}
+
+// Names returns the enabled flags, sorted
+func (f Features) Names() []string {
+	var names []string
+	for name, enabled := range f {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// featureName normalizes the name of a flag
+func featureName(name string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(name), "_", "-", -1))
+}
+
+// featureKey returns the key of the override of a flag
+func featureKey(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// parseFeatures sets field, a Features, from the list in info.key and the
+// overrides, whose keys are prefixed by prefix too, returning the source of
+// the list
+func parseFeatures(field reflect.Value, info tagInfo, fieldPath, prefix string, o *options) (string, error) {
+	overridePrefix := info.featurePrefix
+	if overridePrefix == "" {
+		overridePrefix = "FEATURE_"
+	}
+	overridePrefix = prefix + overridePrefix
+
+	value, source, err := get(info, fieldPath, o)
+	if err != nil {
+		return source, err
+	}
+	features := Features{}
+	for _, name := range strings.Split(value, info.separator) {
+		if name = featureName(name); name != "" {
+			features[name] = true
+		}
+	}
+
+	overrides := map[string]bool{}
+	for name := range features {
+		overrides[featureKey(overridePrefix, name)] = true
+	}
+	if lister, ok := o.lookuper.(KeyLister); ok {
+		for _, key := range lister.Keys() {
+			if strings.HasPrefix(key, overridePrefix) && len(key) > len(overridePrefix) && key != info.key {
+				overrides[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errorList []string
+	c := o.conversion(info)
+	for _, key := range keys {
+		override, ok, err := o.lookup(key, info.timeout)
+		if err == nil && ok {
+			err = o.bind(key, fieldPath)
+		}
+		if err != nil {
+			errorList = append(errorList, err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		enabled, err := convert(strings.TrimSpace(override), reflect.TypeOf(false), c)
+		if err != nil {
+			errorList = append(errorList, "Invalid value for "+key+": "+err.Error())
+			continue
+		}
+		features[featureName(key[len(overridePrefix):])] = enabled.Bool()
+	}
+	if len(errorList) > 0 {
+		return source, errors.New(strings.Join(errorList, ". "))
+	}
+	field.Set(reflect.ValueOf(features))
+	return source, nil
+}
@@ -0,0 +1,42 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	type config struct {
+		Features env.Features `env:"FEATURES" envDefault:"search"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithNamespaceAudit("FEATURE_"), env.WithLookuper(env.Map{
+		"FEATURES":             "search, beta-ui,dark-mode",
+		"FEATURE_SEARCH":       "false",
+		"FEATURE_NEW_CHECKOUT": "true",
+		"FEATURE_DARK_MODE":    "1",
+	})))
+	assert.False(t, cfg.Features.Enabled("search"))
+	assert.True(t, cfg.Features.Enabled("Beta-UI"))
+	assert.True(t, cfg.Features.Enabled("new_checkout"))
+	assert.Equal(t, []string{"beta-ui", "dark-mode", "new-checkout"}, cfg.Features.Names())
+
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})))
+	assert.Equal(t, []string{"search"}, cfg.Features.Names())
+}
+
+func TestFeaturesOverridePrefix(t *testing.T) {
+	type config struct {
+		Flags env.Features `env:"FLAGS" envFeaturePrefix:"FLAG_"`
+	}
+	cfg := config{}
+	l := env.LookuperFunc(env.Map{"APP_FLAGS": "a,b", "APP_FLAG_B": "off", "APP_FLAG_C": "true"}.Lookup)
+	assert.NoError(t, env.ParseWithPrefix(&cfg, "APP_", env.WithRelaxedBool(), env.WithLookuper(l)))
+	assert.Equal(t, []string{"a"}, cfg.Flags.Names())
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"FLAGS": "a", "FLAG_A": "maybe"}))
+	assert.Equal(t, errors.New(`Invalid value for FLAG_A: strconv.ParseBool: parsing "maybe": invalid syntax`), err)
+}
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldError is returned by Parse when parsing a field panicked, e.g.
+// because a custom parser returned a value of the wrong type or a field
+// can't be set. The panic is recovered and the parse stops, so a bad field
+// doesn't crash the process at startup.
+type FieldError struct {
+	// Field is the path of the field, e.g. `Database.Port`
+	Field string
+	// Key is the variable of the field, empty if it has none
+	Key string
+	// Err describes the panic
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	field := e.Field
+	if e.Key != "" {
+		field += " (" + e.Key + ")"
+	}
+	return "Field " + field + " couldn't be parsed: " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// safeParse fills the struct ref like doParse, except that a panic is
+// returned as a *FieldError for the field being parsed
+func (o *options) safeParse(ref reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o.errors++
+			err = &FieldError{Field: o.currentPath, Key: o.currentKey, Err: panicError(r)}
+		}
+	}()
+	return doParse(ref, "", o.prefix, o)
+}
+
+// panicError returns the value of a recovered panic as an error
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
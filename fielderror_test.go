@@ -0,0 +1,51 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type bomb struct{}
+
+func TestFieldError(t *testing.T) {
+	type database struct {
+		Host  string `env:"DB_HOST"`
+		Trap  bomb   `env:"DB_TRAP"`
+		After string `env:"DB_AFTER"`
+	}
+	type config struct {
+		Database database
+	}
+	parsers := env.CustomParsers{
+		reflect.TypeOf(bomb{}): func(v string) (interface{}, error) {
+			panic("boom: " + v)
+		},
+	}
+
+	var cfg config
+	err := env.ParseWithFuncs(&cfg, parsers, env.WithLookuper(env.Map{
+		"DB_HOST":  "localhost",
+		"DB_TRAP":  "x",
+		"DB_AFTER": "y",
+	}))
+	var fieldErr *env.FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Database.Trap", fieldErr.Field)
+	assert.Equal(t, "DB_TRAP", fieldErr.Key)
+	assert.EqualError(t, err, "Field Database.Trap (DB_TRAP) couldn't be parsed: boom: x")
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Empty(t, cfg.Database.After)
+
+	assert.NoError(t, env.ParseWithFuncs(&cfg, parsers, env.WithLookuper(env.Map{})))
+
+	type unexported struct {
+		port int `env:"PORT"`
+	}
+	err = env.Parse(&unexported{}, env.WithLookuper(env.Map{"PORT": "8080"}))
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "port", fieldErr.Field)
+}
@@ -0,0 +1,58 @@
+package env
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// WithFields restricts parsing to the fields at the given paths, such as
+// `Database` or `Server.TLS`, and the fields nested in them, leaving the
+// others untouched. This allows a subsystem to reparse its own section of a
+// shared config, e.g. on reload. Namespace audits are skipped, as the
+// variables of the other fields are not read.
+func WithFields(paths ...string) Option {
+	return func(o *options) {
+		if o.only == nil {
+			o.only = map[string]bool{}
+		}
+		for _, p := range paths {
+			o.only[p] = false
+		}
+	}
+}
+
+// selected reports whether the field at fieldPath must be parsed, because
+// it is selected, nested in a selected field or contains one
+func (o *options) selected(fieldPath string) bool {
+	if o.only == nil {
+		return true
+	}
+	ok := false
+	for p := range o.only {
+		switch {
+		case p == fieldPath:
+			o.only[p] = true
+			ok = true
+		case strings.HasPrefix(fieldPath, p+".") || strings.HasPrefix(fieldPath, p+"["),
+			strings.HasPrefix(p, fieldPath+"."):
+			ok = true
+		}
+	}
+	return ok
+}
+
+// unmatchedFields reports the paths given to WithFields that match no field
+func (o *options) unmatchedFields() error {
+	var errorList []string
+	for p, found := range o.only {
+		if !found {
+			errorList = append(errorList, "Field "+p+" not found")
+		}
+	}
+	if len(errorList) == 0 {
+		return nil
+	}
+	sort.Strings(errorList)
+	return errors.New(strings.Join(errorList, ". "))
+}
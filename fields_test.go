@@ -0,0 +1,42 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFields(t *testing.T) {
+	type tls struct {
+		Cert string `env:"TLS_CERT"`
+	}
+	type server struct {
+		Port int `env:"PORT"`
+		TLS  *tls
+	}
+	type config struct {
+		Database string `env:"DATABASE_URL"`
+		Debug    bool   `env:"DEBUG"`
+		Server   *server
+	}
+	lookuper := env.WithLookuper(env.Map{
+		"DATABASE_URL": "postgres://new",
+		"DEBUG":        "true",
+		"PORT":         "8080",
+		"TLS_CERT":     "new.pem",
+	})
+
+	cfg := config{Database: "postgres://old", Server: &server{Port: 80, TLS: &tls{Cert: "old.pem"}}}
+	assert.NoError(t, env.Parse(&cfg, lookuper, env.WithFields("Database", "Server.TLS")))
+	assert.Equal(t, "postgres://new", cfg.Database)
+	assert.False(t, cfg.Debug)
+	assert.Equal(t, 80, cfg.Server.Port)
+	assert.Equal(t, "new.pem", cfg.Server.TLS.Cert)
+
+	assert.NoError(t, env.Parse(&cfg, lookuper, env.WithFields("Server")))
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.False(t, cfg.Debug)
+
+	assert.EqualError(t, env.Parse(&cfg, lookuper, env.WithFields("Server.Port", "Databse")), "Field Databse not found")
+}
@@ -0,0 +1,113 @@
+package env
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns a stable hash of the values of the fields of cfg
+// loaded from environment variables, as a hex string, so orchestration can
+// tell whether a config changed and logs can refer to a config version.
+// opts are the options of the parse, such as WithSpec, which decide the
+// fields read. Both structs and pointers to structs are accepted;
+// Fingerprint returns "" for other values.
+//
+// Secrets only contribute whether they are set, as a hash of a low-entropy
+// secret could be brute-forced, unless a key is given with
+// WithFingerprintKey: the fingerprint is then an HMAC-SHA256 of all values,
+// so rotating a secret changes it. Lazy fields only contribute their key,
+// as they are resolved when read.
+func Fingerprint(cfg interface{}, opts ...Option) string {
+	ref := indirect(reflect.ValueOf(cfg))
+	if ref.Kind() != reflect.Struct {
+		return ""
+	}
+	o := newOptions(context.Background(), opts)
+	h := sha256.New()
+	if o.fingerprintKey != nil {
+		h = hmac.New(sha256.New, o.fingerprintKey)
+	}
+	o.fingerprintStruct(h, ref, "", "")
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithFingerprintKey makes Fingerprint an HMAC-SHA256 with key, which
+// includes the values of secrets. The key must be kept as secret as they
+// are.
+func WithFingerprintKey(key []byte) Option {
+	return func(o *options) {
+		o.fingerprintKey = key
+	}
+}
+
+func (o *options) fingerprintStruct(h hash.Hash, ref reflect.Value, path, prefix string) {
+	// stop at pointer cycles
+	if strings.Count(path, ".") > defaultMaxDepth {
+		return
+	}
+	refType := ref.Type()
+	for i := 0; i < refType.NumField(); i++ {
+		field := refType.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		fieldPath := path + field.Name
+		info, err := o.prefixedInfo(field, fieldPath, prefix)
+		if err != nil {
+			continue
+		}
+		if info.key == "" {
+			if isStructOrStructPtr(field.Type) {
+				o.fingerprintStruct(h, indirect(ref.Field(i)), fieldPath+".", o.nestedPrefix(prefix, field.Name, info))
+			}
+			continue
+		}
+		// the field and its key are hashed too, so that swapping values
+		// between fields changes the fingerprint
+		io.WriteString(h, fieldPath+"\x00"+info.key+"\x00")
+		h.Write(o.fingerprintValue(ref.Field(i), info))
+		io.WriteString(h, "\x00")
+	}
+}
+
+// fingerprintValue returns a stable encoding of v: JSON, which follows
+// pointers and sorts map keys, or its default format if it can't be
+// encoded. Secrets, which are redacted in JSON, are hashed as is with a key.
+func (o *options) fingerprintValue(v reflect.Value, info tagInfo) []byte {
+	t := v.Type()
+	if isRuntimeType(t, o.converters) || reflect.PtrTo(t).Implements(lazyFieldType) {
+		return nil
+	}
+	if reflect.PtrTo(t).Implements(currentValuerType) {
+		if !v.CanAddr() {
+			return nil
+		}
+		// read under its lock rather than copied
+		v = reflect.ValueOf(v.Addr().Interface().(currentValuer).current())
+		if !v.IsValid() {
+			return nil
+		}
+	}
+	secret := info.secret || isSecretType(t)
+	if secret && o.fingerprintKey == nil {
+		return []byte(strconv.FormatBool(!v.IsZero()))
+	}
+	if s, ok := v.Interface().(SecretString); ok {
+		return s.Bytes()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return []byte(fmt.Sprintf("%v", v.Interface()))
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	type config struct {
+		Host     string            `env:"HOST"`
+		Port     *int              `env:"PORT"`
+		Password string            `env:"PASSWORD,secret"`
+		Limits   map[string]int    `env:"LIMITS"`
+		Timeout  time.Duration     `env:"TIMEOUT"`
+		Upstream *upstream         `envPrefix:"UP_"`
+		Labels   map[string]string // not read from the environment
+	}
+	l := env.Map{
+		"HOST":     "localhost",
+		"PORT":     "8080",
+		"PASSWORD": "secret",
+		"LIMITS":   "read:10,write:5,admin:1",
+		"TIMEOUT":  "5s",
+		"UP_HOST":  "up",
+	}
+	parse := func(l env.Map) config {
+		cfg := config{Upstream: &upstream{}}
+		assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l)))
+		return cfg
+	}
+	a, b := parse(l), parse(l)
+	fp := env.Fingerprint(&a)
+	assert.Len(t, fp, 64)
+	assert.Equal(t, fp, env.Fingerprint(b))
+	assert.NotContains(t, fp, "secret")
+
+	b.Labels = map[string]string{"team": "core"}
+	assert.Equal(t, fp, env.Fingerprint(b))
+
+	for key, value := range map[string]string{"UP_HOST": "other", "LIMITS": "read:10", "PASSWORD": ""} {
+		changed := env.Map{}
+		for k, v := range l {
+			changed[k] = v
+		}
+		changed[key] = value
+		c := parse(changed)
+		assert.NotEqual(t, fp, env.Fingerprint(c), key)
+	}
+
+	// secrets are only hashed with a key
+	rotated := env.Map{}
+	for k, v := range l {
+		rotated[k] = v
+	}
+	rotated["PASSWORD"] = "rotated"
+	c := parse(rotated)
+	assert.Equal(t, fp, env.Fingerprint(c))
+	key := env.WithFingerprintKey([]byte("key"))
+	assert.NotEqual(t, env.Fingerprint(a, key), env.Fingerprint(c, key))
+	assert.NotEqual(t, fp, env.Fingerprint(a, key))
+
+	assert.Equal(t, "", env.Fingerprint("not a struct"))
+}
+
+func TestFingerprintOptions(t *testing.T) {
+	type config struct {
+		Host  string
+		Token env.Lazy[string]       `env:"TOKEN"`
+		Level env.Refreshing[string] `env:"LEVEL"`
+	}
+	spec := env.WithSpec(env.NewSpec().Field("Host", "HOST"))
+	parse := func(l env.Map) *config {
+		cfg := &config{}
+		assert.NoError(t, env.Parse(cfg, env.WithLookuper(l), spec))
+		return cfg
+	}
+	a := parse(env.Map{"HOST": "a", "LEVEL": "info"})
+	assert.Equal(t, env.Fingerprint(a, spec), env.Fingerprint(parse(env.Map{"HOST": "a", "LEVEL": "info", "TOKEN": "t"}), spec))
+	assert.NotEqual(t, env.Fingerprint(a, spec), env.Fingerprint(parse(env.Map{"HOST": "b", "LEVEL": "info"}), spec))
+	assert.NotEqual(t, env.Fingerprint(a, spec), env.Fingerprint(parse(env.Map{"HOST": "a", "LEVEL": "debug"}), spec))
+}
+
+func TestFingerprintCycle(t *testing.T) {
+	n := &node{Name: "a"}
+	n.Next = n
+	assert.Len(t, env.Fingerprint(n), 64)
+}
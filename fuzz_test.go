@@ -0,0 +1,89 @@
+package env_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+)
+
+// fuzzConfig has a field of each kind of value the parser splits
+type fuzzConfig struct {
+	Strings   []string             `env:"VALUE"`
+	Quoted    []string             `env:"VALUE,quoted"`
+	Trimmed   []string             `env:"VALUE,trimItems,skipEmpty"`
+	Piped     []string             `env:"VALUE,quoted" envSeparator:"||"`
+	Ints      []int                `env:"VALUE"`
+	Map       map[string]string    `env:"VALUE"`
+	QuotedMap map[string]int       `env:"VALUE,quoted,trimItems"`
+	Pairs     map[string]float64   `env:"VALUE" envSeparator:";" envKeyValSeparator:"="`
+	Set       env.Set[string]      `env:"VALUE,quoted"`
+	Durations []time.Duration      `env:"VALUE,extendedDuration"`
+	Range     env.Range[int]       `env:"VALUE"`
+	Weighted  env.Weighted[string] `env:"VALUE"`
+	Money     env.Money            `env:"VALUE" envCurrency:"USD"`
+	Percent   env.Percent          `env:"VALUE"`
+	Language  env.LanguageTag      `env:"VALUE"`
+	URLs      []url.URL            `env:"VALUE"`
+	Unescaped string               `env:"VALUE,unescapeNewlines,trim"`
+}
+
+func FuzzParseValue(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"a,b,c",
+		`"a,b",c`,
+		`"a""b",\,c`,
+		`"unterminated`,
+		`\`,
+		"k1:v1,k2:v2",
+		`"k,1": 2 , k2:3`,
+		"a=1.5;b=2",
+		"a||b||",
+		"1w2d,3h",
+		"8000-8999",
+		"-5--1",
+		"a:3,b:1",
+		"12.50 USD",
+		"75%",
+		"en-US",
+		"https://example.com/a?b=c",
+		"line\\nbreak",
+		"\xff\xfe,\x00:\x00",
+		`"a:b":"c,d",e\:f:g`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		var cfg fuzzConfig
+		err := env.Parse(&cfg, env.WithLookuper(env.Map{"VALUE": value}))
+		// Parse recovers panics, returning them as a FieldError
+		var fieldErr *env.FieldError
+		if errors.As(err, &fieldErr) {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzParseDotEnv(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"A=1\nexport B='two'\n# comment\n",
+		"C=\"multi\nline \\\"quoted\\\" \\$HOME\"\n",
+		"D=${A:-default} $B ${C} # inline\n",
+		"E=\"unterminated",
+		"F='unterminated",
+		"=no key",
+		"G=${",
+		"H=${UNCLOSED:-",
+		"\xff=\xfe",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		_, _ = env.ParseDotEnv(strings.NewReader(content), ".env")
+	})
+}
@@ -0,0 +1,76 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Handler returns an http.Handler rendering the effective config for a debug
+// endpoint: each field with its variable, value and source, with secrets
+// redacted. v is either a *Holder, whose current config is rendered, or a
+// pointer to a struct, for which a `DryRun` with opts is rendered, reflecting
+// the current environment. A Holder is parsed with the options given to
+// NewHolder, so opts can't be given with one.
+//
+// The report is rendered as JSON, or as an HTML table when the request
+// accepts text/html or has the `format=html` query parameter.
+func Handler(v interface{}, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := handlerReport(v, opts)
+		if report == nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// rendered first, so that errors are reported with a status
+		var buf bytes.Buffer
+		contentType := "application/json"
+		if r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+			contentType = "text/html; charset=utf-8"
+			err = reportTemplate.Execute(&buf, report)
+		} else {
+			enc := json.NewEncoder(&buf)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+func handlerReport(v interface{}, opts []Option) (*Report, error) {
+	if h, ok := v.(*Holder); ok {
+		if len(opts) > 0 {
+			return nil, errors.New("Options can't be given with a Holder, give them to NewHolder")
+		}
+		return h.Report(), nil
+	}
+	return DryRun(v, opts...)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"value": func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Configuration</title></head>
+<body>
+<table>
+<tr><th>Field</th><th>Variable</th><th>Value</th><th>Source</th><th>Error</th></tr>
+{{range .Fields}}<tr><td>{{.Field}}</td><td>{{.Key}}</td><td>{{value .Value}}</td><td>{{.Source}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
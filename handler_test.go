@@ -0,0 +1,62 @@
+package env_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	h, err := env.NewHolder(func() interface{} { return &reportConfig{} }, env.WithLookuper(env.Map{
+		"PORT":     "8080",
+		"PASSWORD": "hunter2",
+	}))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	env.Handler(h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"fields": [
+		{"field": "Host", "key": "HOST", "source": "default", "value": "localhost"},
+		{"field": "Port", "key": "PORT", "source": "environment", "value": 8080},
+		{"field": "Password", "key": "PASSWORD", "source": "environment", "value": "[REDACTED]", "secret": true},
+		{"field": "Debug", "key": "DEBUG", "source": "unset", "value": false}
+	]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	env.Handler(h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config?format=html", nil))
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<tr><td>Port</td><td>PORT</td><td>8080</td><td>environment</td><td></td></tr>")
+	assert.NotContains(t, w.Body.String(), "hunter2")
+}
+
+func TestHandlerDryRun(t *testing.T) {
+	w := httptest.NewRecorder()
+	handler := env.Handler(&reportConfig{}, env.WithLookuper(env.Map{"PORT": "x"}))
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	assert.Contains(t, w.Body.String(), `"error": "strconv.ParseInt: parsing \"x\": invalid syntax"`)
+
+	w = httptest.NewRecorder()
+	env.Handler(1).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	type config struct {
+		Ratio float64 `env:"RATIO"`
+	}
+	w := httptest.NewRecorder()
+	env.Handler(&config{}, env.WithLookuper(env.Map{"RATIO": "NaN"})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "json: unsupported value: NaN\n", w.Body.String())
+
+	h, err := env.NewHolder(func() interface{} { return &reportConfig{} }, env.WithLookuper(env.Map{}))
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	env.Handler(h, env.WithLookuper(env.Map{})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Options can't be given with a Holder, give them to NewHolder\n", w.Body.String())
+}
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	implementationsMu sync.RWMutex
+	// implementations maps interface types to their implementations, by name
+	implementations = map[reflect.Type]map[string]reflect.Type{}
+)
+
+// RegisterImplementation registers impl, a pointer to a struct implementing
+// the interface I, as the implementation of I named name. A field of type I
+// is then parsed by reading the name of its implementation from its
+// variable, e.g. `STORAGE_DRIVER=s3` for a field tagged
+// `env:"STORAGE_DRIVER" envPrefix:"STORAGE_"`, and filling a new struct of
+// that implementation, whose keys are prefixed as a nested struct's:
+//
+//	env.RegisterImplementation[StorageConfig]("s3", &S3Config{})
+//
+// Plugins typically register their implementations in an init function.
+func RegisterImplementation[I any](name string, impl I) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	t := reflect.TypeOf(impl)
+	if iface.Kind() != reflect.Interface || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("env: RegisterImplementation expects an interface type and a pointer to a struct")
+	}
+	implementationsMu.Lock()
+	defer implementationsMu.Unlock()
+	if implementations[iface] == nil {
+		implementations[iface] = map[string]reflect.Type{}
+	}
+	implementations[iface][name] = t
+}
+
+// implementationsOf returns the implementations registered for t, if it is
+// an interface type
+func implementationsOf(t reflect.Type) map[string]reflect.Type {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	implementationsMu.RLock()
+	defer implementationsMu.RUnlock()
+	return implementations[t]
+}
+
+// parseImplementation sets field, an interface, to a new struct of the
+// implementation named by the field's variable, parsed with the prefix of
+// the field. It returns the source of the implementation's name.
+func parseImplementation(field reflect.Value, info tagInfo, fieldPath, prefix string, o *options) (string, error) {
+	name, source, err := get(info, fieldPath, o)
+	if err != nil || name == "" {
+		return source, err
+	}
+	impls := implementationsOf(field.Type())
+	t, ok := impls[name]
+	if !ok {
+		names := make([]string, 0, len(impls))
+		for n := range impls {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return source, errors.New("Invalid " + info.key + " " + name + ", expected one of " + strings.Join(names, ", "))
+	}
+	elem, ref := newStruct(t)
+	if err := o.enter(ref, fieldPath); err != nil {
+		return source, err
+	}
+	if err := doParse(ref, fieldPath+".", o.nestedPrefix(prefix, fieldName(fieldPath), info), o); err != nil {
+		return source, err
+	}
+	field.Set(elem)
+	return source, nil
+}
+
+// fieldName returns the name of the field at fieldPath
+func fieldName(fieldPath string) string {
+	return fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+}
@@ -0,0 +1,57 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type storageConfig interface {
+	driver() string
+}
+
+type s3Config struct {
+	Bucket string `env:"BUCKET,required"`
+	Region string `env:"REGION" envDefault:"us-east-1"`
+}
+
+func (*s3Config) driver() string { return "s3" }
+
+type fsConfig struct {
+	Root string `env:"ROOT" envDefault:"/var/lib/app"`
+}
+
+func (*fsConfig) driver() string { return "fs" }
+
+func init() {
+	env.RegisterImplementation[storageConfig]("s3", &s3Config{})
+	env.RegisterImplementation[storageConfig]("fs", &fsConfig{})
+}
+
+func TestRegisterImplementation(t *testing.T) {
+	type config struct {
+		Storage storageConfig `env:"STORAGE_DRIVER" envPrefix:"STORAGE_"`
+	}
+
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"STORAGE_DRIVER": "s3",
+		"STORAGE_BUCKET": "backups",
+	})))
+	assert.Equal(t, &s3Config{Bucket: "backups", Region: "us-east-1"}, cfg.Storage)
+
+	cfg = config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"STORAGE_DRIVER": "fs"})))
+	assert.Equal(t, &fsConfig{Root: "/var/lib/app"}, cfg.Storage)
+
+	cfg = config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})))
+	assert.Nil(t, cfg.Storage)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"STORAGE_DRIVER": "gcs"}))
+	assert.EqualError(t, err, "Invalid STORAGE_DRIVER gcs, expected one of fs, s3")
+
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"STORAGE_DRIVER": "s3"}))
+	assert.EqualError(t, err, "Required environment variable STORAGE_BUCKET is not set")
+}
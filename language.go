@@ -0,0 +1,103 @@
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// LanguageTag is a BCP 47 language tag, such as `pt-BR` or `zh-Hant-TW`,
+// whose syntax is validated by Parse. Its subtags are normalized to their
+// conventional case, e.g. `PT-br` is read as `pt-BR`. Use the presets/language
+// package for golang.org/x/text/language.Tag fields, which are also checked
+// against the registry of known subtags.
+type LanguageTag string
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *LanguageTag) UnmarshalText(text []byte) error {
+	tag, ok := normalizeLanguageTag(string(text))
+	if !ok {
+		return errors.New("Invalid language tag " + string(text))
+	}
+	*l = LanguageTag(tag)
+	return nil
+}
+
+// normalizeLanguageTag checks the syntax of a BCP 47 tag and returns it with
+// its subtags in their conventional case
+func normalizeLanguageTag(s string) (string, bool) {
+	subtags := strings.Split(strings.Replace(s, "_", "-", -1), "-")
+	for i, sub := range subtags {
+		if sub == "" || len(sub) > 8 || !isAlnum(sub) {
+			return "", false
+		}
+		subtags[i] = strings.ToLower(sub)
+	}
+	if subtags[0] == "x" {
+		return strings.Join(subtags, "-"), len(subtags) > 1
+	}
+	if n := len(subtags[0]); n < 2 || n == 4 || !isAlpha(subtags[0]) {
+		return "", false
+	}
+	i := 1
+	// extended language subtags
+	for j := 0; j < 3 && i < len(subtags) && len(subtags[i]) == 3 && isAlpha(subtags[i]); j++ {
+		i++
+	}
+	if i < len(subtags) && len(subtags[i]) == 4 && isAlpha(subtags[i]) {
+		subtags[i] = strings.ToUpper(subtags[i][:1]) + subtags[i][1:]
+		i++
+	}
+	if i < len(subtags) && (len(subtags[i]) == 2 && isAlpha(subtags[i]) || len(subtags[i]) == 3 && isDigits(subtags[i])) {
+		subtags[i] = strings.ToUpper(subtags[i])
+		i++
+	}
+	for i < len(subtags) && (len(subtags[i]) >= 5 || len(subtags[i]) == 4 && isDigits(subtags[i][:1])) {
+		i++
+	}
+	// extensions and private use, introduced by a single character
+	for i < len(subtags) {
+		singleton := subtags[i]
+		if len(singleton) != 1 {
+			return "", false
+		}
+		i++
+		if singleton == "x" {
+			return strings.Join(subtags, "-"), i < len(subtags)
+		}
+		start := i
+		for i < len(subtags) && len(subtags[i]) >= 2 {
+			i++
+		}
+		if i == start {
+			return "", false
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
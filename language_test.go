@@ -0,0 +1,30 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageTag(t *testing.T) {
+	type config struct {
+		Locale    env.LanguageTag   `env:"LOCALE"`
+		Supported []env.LanguageTag `env:"SUPPORTED"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"LOCALE":    "PT-br",
+		"SUPPORTED": "en,zh-hant-tw,es-419,de-CH-1996,sr-Latn-RS-u-nu-latn,en_US,x-private,en-a-bbb-x-a-ccc",
+	})))
+	assert.Equal(t, env.LanguageTag("pt-BR"), cfg.Locale)
+	assert.Equal(t, []env.LanguageTag{
+		"en", "zh-Hant-TW", "es-419", "de-CH-1996", "sr-Latn-RS-u-nu-latn", "en-US", "x-private", "en-a-bbb-x-a-ccc",
+	}, cfg.Supported)
+
+	for _, v := range []string{"e", "engl", "en--US", "en-US-u", "en-verylongsubtag", "en-US-x", "pt-BR!", "en-a-b"} {
+		err := env.Parse(&cfg, env.WithLookuper(env.Map{"LOCALE": v}))
+		assert.Equal(t, errors.New("Invalid language tag "+v), err, v)
+	}
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Lazy is a field whose variable is resolved on the first call to Get rather
+// than by Parse, for expensive or rarely needed values such as credentials
+// used by an optional code path:
+//
+//	type config struct {
+//		Token env.Lazy[string] `env:"TOKEN,required"`
+//	}
+//
+// Lookups made by Get use a background context. A Lazy must not be copied
+// after Parse.
+type Lazy[T any] struct {
+	once    sync.Once
+	resolve func(reflect.Type) (reflect.Value, error)
+	value   T
+	err     error
+}
+
+// Get resolves and parses the variable on the first call and returns the
+// same value and error afterwards
+func (l *Lazy[T]) Get() (T, error) {
+	l.once.Do(func() {
+		if l.resolve == nil {
+			l.err = errors.New("Lazy field was not parsed")
+			return
+		}
+		v, err := l.resolve(reflect.TypeOf(&l.value).Elem())
+		if err != nil {
+			l.err = err
+			return
+		}
+		if v.IsValid() {
+			l.value = v.Interface().(T)
+		}
+	})
+	return l.value, l.err
+}
+
+func (l *Lazy[T]) setResolver(resolve func(reflect.Type) (reflect.Value, error)) {
+	l.resolve = resolve
+}
+
+// lazyField is implemented by Lazy fields
+type lazyField interface {
+	setResolver(func(reflect.Type) (reflect.Value, error))
+}
+
+var lazyFieldType = reflect.TypeOf((*lazyField)(nil)).Elem()
+
+// resolver returns a function resolving and converting the variable of a
+// Lazy or Refreshing field once Parse returned, with options of its own
+func (o *options) resolver(info tagInfo) func(reflect.Type) (reflect.Value, error) {
+	base := o.detached()
+	return func(t reflect.Type) (reflect.Value, error) {
+		return resolveAs(info, t, base.detached())
+	}
+}
+
+// resolveAs resolves the variable of the field described by info and
+// converts it to t
+func resolveAs(info tagInfo, t reflect.Type, o *options) (reflect.Value, error) {
+	value, _, err := resolve(info, o)
+	if err != nil || value == "" {
+		return reflect.Value{}, err
+	}
+	if err := info.checkOneOf(value, t); err != nil {
+		return reflect.Value{}, err
+	}
+	return convert(value, t, o.conversion(info))
+}
+
+// detached returns a copy of o for lookups made after Parse returned, which
+// use a background context and don't update the state of the parse
+func (o *options) detached() *options {
+	lo := *o
+	lo.ctx = context.Background()
+	lo.prefetched = nil
+	lo.report, lo.result, lo.explain = nil, nil, nil
+	lo.observed = nil
+	lo.consumed = map[string]string{}
+	lo.specUsed = map[string]bool{}
+	lo.visiting = map[uintptr]string{}
+	lo.missing = nil
+	lo.lookuper, lo.live = lo.liveLookuper(), nil
+	return &lo
+}
+
+// asLazy returns field as a lazyField, if it is a settable Lazy
+func asLazy(field reflect.Value) (lazyField, bool) {
+	if !field.CanSet() {
+		return nil, false
+	}
+	lazy, ok := field.Addr().Interface().(lazyField)
+	return lazy, ok
+}
@@ -0,0 +1,62 @@
+package env_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	type config struct {
+		Token   env.Lazy[string] `env:"TOKEN,required"`
+		Port    env.Lazy[int]    `env:"PORT" envDefault:"3000"`
+		Missing env.Lazy[string] `env:"MISSING,required"`
+		Invalid env.Lazy[int]    `env:"INVALID"`
+	}
+	l := &countingLookuper{Map: env.Map{"TOKEN": "s3cr3t", "INVALID": "x"}}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l)))
+	assert.Equal(t, 0, l.calls)
+
+	token, err := cfg.Token.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+	token, err = cfg.Token.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+	assert.Equal(t, 1, l.calls)
+
+	port, err := cfg.Port.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, port)
+
+	_, err = cfg.Missing.Get()
+	assert.EqualError(t, err, "Required environment variable MISSING is not set")
+	_, err = cfg.Invalid.Get()
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "x": invalid syntax`)
+
+	var unparsed env.Lazy[string]
+	_, err = unparsed.Get()
+	assert.EqualError(t, err, "Lazy field was not parsed")
+}
+
+func TestLazyConcurrentGets(t *testing.T) {
+	type config struct {
+		Token    env.Lazy[string] `env:"TOKEN,required"`
+		Password env.Lazy[string] `env:"PASSWORD,required"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})))
+	var wg sync.WaitGroup
+	for _, lazy := range []*env.Lazy[string]{&cfg.Token, &cfg.Password} {
+		wg.Add(1)
+		go func(lazy *env.Lazy[string]) {
+			defer wg.Done()
+			_, err := lazy.Get()
+			assert.Error(t, err)
+		}(lazy)
+	}
+	wg.Wait()
+}
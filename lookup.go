@@ -0,0 +1,122 @@
+package env
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Lookuper retrieves the values of environment variables. The default
+// Lookuper reads the process environment.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// ContextLookuper is implemented by Lookupers that may block or fail, such as
+// remote secret stores. When a Lookuper implements it, LookupContext is used
+// instead of Lookup, receiving the context given to ParseContext.
+type ContextLookuper interface {
+	Lookuper
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// KeyLister is implemented by Lookupers that can list the keys they hold,
+// which some features such as `WithNamespaceAudit` require
+type KeyLister interface {
+	Keys() []string
+}
+
+// listKeys returns the keys of lister, sorted, whatever the order it lists
+// them in, so errors and warnings about them are reported in a stable order
+func listKeys(lister KeyLister) []string {
+	keys := append([]string(nil), lister.Keys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+// Provenancer is implemented by Lookupers that can tell where the value of a
+// key comes from, such as the file and line of a .env file. It is mentioned
+// in the errors of values that can't be parsed, so operators fix the right
+// place.
+type Provenancer interface {
+	// Provenance returns a description of the origin of the value of key,
+	// e.g. "value from .env file ./.env line 12", or "" if unknown
+	Provenance(key string) string
+}
+
+// LookuperFunc is an adapter to allow the use of ordinary functions as
+// Lookupers, e.g. `LookuperFunc(os.LookupEnv)`
+type LookuperFunc func(key string) (string, bool)
+
+// Lookup calls f(key)
+func (f LookuperFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// Map is a Lookuper backed by a map of keys to values
+type Map map[string]string
+
+// Lookup returns the value stored for key, if any
+func (m Map) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Keys returns the keys of the map, sorted
+func (m Map) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot returns a copy of the process environment, which isn't affected
+// by later calls to os.Setenv
+func Snapshot() Map {
+	m := Map{}
+	for _, kv := range os.Environ() {
+		// entries without `=` can be passed by the parent process
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// WithSnapshot makes Parse read a snapshot of the process environment taken
+// when it starts, so concurrent calls to os.Setenv, e.g. by tests or
+// plugins, can't make it read the variables of different fields at
+// different times. Lazy and Refreshing fields, which are read after Parse
+// returns, and Drifted still read the process environment.
+func WithSnapshot() Option {
+	return func(o *options) {
+		withSnapshot(Snapshot())(o)
+	}
+}
+
+// withSnapshot makes Parse read m, a snapshot of the process environment
+// shared by several calls, as WithSnapshot does
+func withSnapshot(m Map) Option {
+	return func(o *options) {
+		o.lookuper, o.live = m, osLookuper{}
+	}
+}
+
+// osLookuper reads the process environment
+type osLookuper struct{}
+
+func (osLookuper) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (osLookuper) Keys() []string {
+	var keys []string
+	for _, kv := range os.Environ() {
+		keys = append(keys, strings.SplitN(kv, "=", 2)[0])
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FieldSpec describes a value read by `ParseMapSpec`
+type FieldSpec struct {
+	// Key is the name of the environment variable
+	Key string
+	// Type is the type of the value, string if nil
+	Type      reflect.Type
+	Default   string
+	Required  bool
+	Secret    bool
+	Separator string
+	OneOf     []string
+}
+
+// ParseMapSpec reads the variables described by spec into a map with the
+// same keys, for programs that don't know the shape of their config at
+// compile time, such as plugin systems. Values are converted to the type of
+// their FieldSpec as Parse would, and unset values without default are left
+// out.
+func ParseMapSpec(spec map[string]FieldSpec, opts ...Option) (map[string]interface{}, error) {
+	names := make([]string, 0, len(spec))
+	for name := range spec {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := NewSpec()
+	fields := make([]reflect.StructField, 0, len(names))
+	for i, name := range names {
+		fs := spec[name]
+		t := fs.Type
+		if t == nil {
+			t = reflect.TypeOf("")
+		}
+		// names can't be used as field names, which must be identifiers
+		field := "F" + strconv.Itoa(i)
+		fields = append(fields, reflect.StructField{Name: field, Type: t})
+		fieldOpts := []FieldOption{Default(fs.Default)}
+		if fs.Required {
+			fieldOpts = append(fieldOpts, Required())
+		}
+		if fs.Secret {
+			fieldOpts = append(fieldOpts, Secret())
+		}
+		if fs.Separator != "" {
+			fieldOpts = append(fieldOpts, Separator(fs.Separator))
+		}
+		if len(fs.OneOf) > 0 {
+			fieldOpts = append(fieldOpts, OneOf(fs.OneOf...))
+		}
+		s.Field(field, fs.Key, fieldOpts...)
+	}
+
+	v := reflect.New(reflect.StructOf(fields))
+	r := &Report{}
+	if err := Parse(v.Interface(), append(opts, WithSpec(s), WithReport(r))...); err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for _, f := range r.Fields {
+		set[f.Field] = f.Source != SourceUnset
+	}
+	m := map[string]interface{}{}
+	for i, name := range names {
+		if set["F"+strconv.Itoa(i)] {
+			m[name] = v.Elem().Field(i).Interface()
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,36 @@
+package env_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMapSpec(t *testing.T) {
+	spec := map[string]env.FieldSpec{
+		"port":    {Key: "PORT", Type: reflect.TypeOf(0), Default: "3000"},
+		"timeout": {Key: "TIMEOUT", Type: reflect.TypeOf(time.Duration(0))},
+		"hosts":   {Key: "HOSTS", Type: reflect.TypeOf([]string(nil)), Separator: ":"},
+		"name":    {Key: "NAME"},
+		"unset":   {Key: "UNSET"},
+	}
+	m, err := env.ParseMapSpec(spec, env.WithLookuper(env.Map{
+		"TIMEOUT": "5s",
+		"HOSTS":   "a:b",
+		"NAME":    "app",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"port":    3000,
+		"timeout": 5 * time.Second,
+		"hosts":   []string{"a", "b"},
+		"name":    "app",
+	}, m)
+
+	spec["level"] = env.FieldSpec{Key: "LEVEL", Required: true, OneOf: []string{"debug", "info"}}
+	_, err = env.ParseMapSpec(spec, env.WithLookuper(env.Map{"PORT": "x"}))
+	assert.EqualError(t, err, `Required environment variable LEVEL is not set. strconv.ParseInt: parsing "x": invalid syntax`)
+}
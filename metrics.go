@@ -0,0 +1,52 @@
+package env
+
+import (
+	"time"
+)
+
+// Metrics receives measurements about config resolution, so they can be
+// exported (e.g. as Prometheus counters and histograms) to alert on
+// misconfiguration patterns across services
+type Metrics interface {
+	// FieldParsed is called for every field loaded into the struct
+	FieldParsed(key string)
+	// DefaultUsed is called when a field falls back to its `envDefault`
+	DefaultUsed(key string)
+	// RequiredMissing is called when a required variable is not set
+	RequiredMissing(key string)
+	// LookupLatency reports how long a lookup from a ContextLookuper took
+	LookupLatency(key string, d time.Duration)
+}
+
+// WithMetrics reports measurements about the parse to m
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+func (o *options) fieldParsed(key string) {
+	o.fields++
+	if o.metrics != nil {
+		o.metrics.FieldParsed(key)
+	}
+}
+
+func (o *options) defaultUsed(key string) {
+	if o.metrics != nil {
+		o.metrics.DefaultUsed(key)
+	}
+}
+
+func (o *options) requiredMissing(key string) {
+	o.missing = append(o.missing, key)
+	if o.metrics != nil {
+		o.metrics.RequiredMissing(key)
+	}
+}
+
+func (o *options) lookupLatency(key string, start time.Time) {
+	if o.metrics != nil {
+		o.metrics.LookupLatency(key, time.Since(start))
+	}
+}
@@ -0,0 +1,47 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	parsed, defaults, missing, lookups []string
+}
+
+func (m *recordingMetrics) FieldParsed(key string)     { m.parsed = append(m.parsed, key) }
+func (m *recordingMetrics) DefaultUsed(key string)     { m.defaults = append(m.defaults, key) }
+func (m *recordingMetrics) RequiredMissing(key string) { m.missing = append(m.missing, key) }
+func (m *recordingMetrics) LookupLatency(key string, d time.Duration) {
+	m.lookups = append(m.lookups, key)
+}
+
+func TestMetrics(t *testing.T) {
+	type config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT" envDefault:"3000"`
+		Password string `env:"PASSWORD,required"`
+	}
+	m := &recordingMetrics{}
+	cfg := config{}
+	assert.Error(t, env.Parse(&cfg, env.WithMetrics(m), env.WithLookuper(env.Map{
+		"HOST": "localhost",
+	})))
+	assert.Equal(t, []string{"HOST", "PORT"}, m.parsed)
+	assert.Equal(t, []string{"PORT"}, m.defaults)
+	assert.Equal(t, []string{"PASSWORD"}, m.missing)
+	assert.Empty(t, m.lookups)
+}
+
+func TestMetricsLookupLatency(t *testing.T) {
+	type config struct {
+		Var string `env:"VAR"`
+	}
+	m := &recordingMetrics{}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithMetrics(m), env.WithLookuper(&flakyLookuper{})))
+	assert.Equal(t, []string{"VAR"}, m.lookups)
+}
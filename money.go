@@ -0,0 +1,134 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of money stored in minor units, such as cents, to avoid
+// the rounding errors of floats. It is parsed from an amount and an ISO 4217
+// currency code, such as `12.34 EUR`. The `envCurrency` tag sets the
+// currency of amounts written without one, and requires it of the others;
+// with the `minorUnits` option, amounts are integers in minor units, such as
+// `1234` for 12.34.
+type Money struct {
+	// Amount in minor units, e.g. 1234 for 12.34 EUR
+	Amount int64
+	// Currency is the ISO 4217 code, e.g. EUR
+	Currency string
+}
+
+var moneyType = reflect.TypeOf(Money{})
+
+// currencyDecimals lists the currencies whose minor unit isn't a hundredth
+var currencyDecimals = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// decimals returns the number of digits of the minor unit of currency
+func decimals(currency string) int {
+	if d, ok := currencyDecimals[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := parseMoney(string(text), "", false)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// String returns the amount in major units and the currency, e.g. `12.34 EUR`
+func (m Money) String() string {
+	amount, sign := m.Amount, ""
+	if amount < 0 {
+		amount, sign = -amount, "-"
+	}
+	s := strconv.FormatInt(amount, 10)
+	if d := decimals(m.Currency); d > 0 {
+		if len(s) <= d {
+			s = strings.Repeat("0", d-len(s)+1) + s
+		}
+		s = s[:len(s)-d] + "." + s[len(s)-d:]
+	}
+	if m.Currency == "" {
+		return sign + s
+	}
+	return sign + s + " " + m.Currency
+}
+
+// parseMoney parses an amount followed or preceded by its currency, which
+// defaults to currency. With minorUnits, the amount is in minor units.
+func parseMoney(v, currency string, minorUnits bool) (Money, error) {
+	parts := strings.Fields(v)
+	amount := ""
+	switch {
+	case len(parts) == 1:
+		amount = parts[0]
+	case len(parts) == 2 && isCurrency(parts[1]):
+		amount = parts[0]
+		if currency != "" && parts[1] != currency {
+			return Money{}, errors.New("Invalid amount " + v + ", expected " + currency)
+		}
+		currency = parts[1]
+	case len(parts) == 2 && isCurrency(parts[0]):
+		amount = parts[1]
+		if currency != "" && parts[0] != currency {
+			return Money{}, errors.New("Invalid amount " + v + ", expected " + currency)
+		}
+		currency = parts[0]
+	default:
+		return Money{}, errors.New("Invalid amount " + v)
+	}
+
+	if minorUnits {
+		n, err := strconv.ParseInt(amount, 10, 64)
+		if err != nil {
+			return Money{}, errors.New("Invalid amount " + v + ", expected an integer in minor units")
+		}
+		return Money{Amount: n, Currency: currency}, nil
+	}
+	d := decimals(currency)
+	whole, fraction := amount, ""
+	if i := strings.Index(amount, "."); i >= 0 {
+		whole, fraction = amount[:i], amount[i+1:]
+	}
+	if len(fraction) > d {
+		return Money{}, errors.New("Invalid amount " + v + ", " + currency + " has " + strconv.Itoa(d) + " decimals")
+	}
+	if strings.ContainsAny(fraction, "+-") {
+		return Money{}, errors.New("Invalid amount " + v)
+	}
+	n, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", d-len(fraction)), 10, 64)
+	if err != nil || whole == "" || whole == "-" || whole == "+" {
+		return Money{}, errors.New("Invalid amount " + v)
+	}
+	return Money{Amount: n, Currency: currency}, nil
+}
+
+// isCurrency reports whether s looks like an ISO 4217 code
+func isCurrency(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney(t *testing.T) {
+	type config struct {
+		Price    env.Money   `env:"PRICE"`
+		Fee      env.Money   `env:"FEE" envCurrency:"EUR"`
+		Limit    env.Money   `env:"LIMIT,minorUnits" envCurrency:"USD"`
+		Yen      env.Money   `env:"YEN"`
+		Discount env.Money   `env:"DISCOUNT"`
+		Tiers    []env.Money `env:"TIERS" envSeparator:";"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"PRICE":    "12.34 EUR",
+		"FEE":      "0.5",
+		"LIMIT":    "1234",
+		"YEN":      "JPY 500",
+		"DISCOUNT": "-1.05 KWD",
+		"TIERS":    "10 GBP;20.5 GBP",
+	})))
+	assert.Equal(t, config{
+		Price:    env.Money{Amount: 1234, Currency: "EUR"},
+		Fee:      env.Money{Amount: 50, Currency: "EUR"},
+		Limit:    env.Money{Amount: 1234, Currency: "USD"},
+		Yen:      env.Money{Amount: 500, Currency: "JPY"},
+		Discount: env.Money{Amount: -1050, Currency: "KWD"},
+		Tiers:    []env.Money{{Amount: 1000, Currency: "GBP"}, {Amount: 2050, Currency: "GBP"}},
+	}, cfg)
+	assert.Equal(t, "12.34 EUR", cfg.Price.String())
+	assert.Equal(t, "0.50 EUR", cfg.Fee.String())
+	assert.Equal(t, "500 JPY", cfg.Yen.String())
+	assert.Equal(t, "-1.050 KWD", cfg.Discount.String())
+}
+
+func TestInvalidMoney(t *testing.T) {
+	type config struct {
+		Price env.Money `env:"PRICE"`
+		Fee   env.Money `env:"FEE" envCurrency:"EUR"`
+		Limit env.Money `env:"LIMIT,minorUnits"`
+	}
+	for _, tt := range []struct {
+		environ  env.Map
+		expected string
+	}{
+		{env.Map{"PRICE": "12.345 EUR"}, "Invalid amount 12.345 EUR, EUR has 2 decimals"},
+		{env.Map{"PRICE": "1.5 JPY"}, "Invalid amount 1.5 JPY, JPY has 0 decimals"},
+		{env.Map{"PRICE": "12,34 EUR"}, "Invalid amount 12,34 EUR"},
+		{env.Map{"PRICE": "1.-5"}, "Invalid amount 1.-5"},
+		{env.Map{"PRICE": "twelve"}, "Invalid amount twelve"},
+		{env.Map{"FEE": "1 USD"}, "Invalid amount 1 USD, expected EUR"},
+		{env.Map{"LIMIT": "12.34"}, "Invalid amount 12.34, expected an integer in minor units"},
+	} {
+		err := env.Parse(&config{}, env.WithLookuper(tt.environ))
+		assert.Equal(t, errors.New(tt.expected), err, tt.environ)
+	}
+}
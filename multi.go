@@ -0,0 +1,27 @@
+package env
+
+// ParseMulti parses each of cfgs, pointers to structs, such as the config
+// types of several packages, with the same options, given among cfgs:
+//
+//	err := env.ParseMulti(&dbCfg, &httpCfg, &logCfg, env.WithPrefix("APP_"))
+//
+// The process environment is read once, so all the structs see the same
+// variables, unless an option sets another Lookuper. Lazy and Refreshing
+// fields still read the process environment when they are used. The errors
+// of all the structs are returned together.
+func ParseMulti(cfgs ...interface{}) error {
+	opts := []Option{withSnapshot(Snapshot())}
+	var structs []interface{}
+	for _, cfg := range cfgs {
+		if opt, ok := cfg.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		structs = append(structs, cfg)
+	}
+	var errs []error
+	for _, cfg := range structs {
+		errs = append(errs, Parse(cfg, opts...))
+	}
+	return joinErrors(errs...)
+}
@@ -0,0 +1,84 @@
+package env_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMulti(t *testing.T) {
+	type dbConfig struct {
+		URL string `env:"DB_URL,required"`
+	}
+	type httpConfig struct {
+		Port int `env:"HTTP_PORT" envDefault:"8080"`
+	}
+	type logConfig struct {
+		Level string `env:"LOG_LEVEL,required"`
+	}
+
+	os.Clearenv()
+	os.Setenv("DB_URL", "postgres://localhost")
+	os.Setenv("LOG_LEVEL", "debug")
+	defer os.Clearenv()
+
+	var db dbConfig
+	var http httpConfig
+	var log logConfig
+	assert.NoError(t, env.ParseMulti(&db, &http, &log))
+	assert.Equal(t, "postgres://localhost", db.URL)
+	assert.Equal(t, 8080, http.Port)
+	assert.Equal(t, "debug", log.Level)
+
+	err := env.ParseMulti(&db, &http, &log, env.WithLookuper(env.Map{"HTTP_PORT": "x"}))
+	assert.EqualError(t, err, "Required environment variable DB_URL is not set. "+
+		`strconv.ParseInt: parsing "x": invalid syntax. `+
+		"Required environment variable LOG_LEVEL is not set")
+
+	assert.Equal(t, env.ErrNotAStructPtr, env.ParseMulti(db))
+}
+
+// setenvValue sets SET_BY_PARSER when parsed, as a custom parser or a prompt
+// could
+type setenvValue string
+
+func (v *setenvValue) UnmarshalText(text []byte) error {
+	os.Setenv("SET_BY_PARSER", "changed")
+	*v = setenvValue(text)
+	return nil
+}
+
+func TestParseMultiReadsOnce(t *testing.T) {
+	type first struct {
+		Value setenvValue `env:"MULTI_VALUE"`
+	}
+	type second struct {
+		Later string `env:"SET_BY_PARSER"`
+	}
+	t.Setenv("MULTI_VALUE", "x")
+	t.Setenv("SET_BY_PARSER", "original")
+
+	var a first
+	var b second
+	assert.NoError(t, env.ParseMulti(&a, &b))
+	assert.Equal(t, "original", b.Later)
+}
+
+func TestParseMultiLazy(t *testing.T) {
+	type config struct {
+		Token env.Lazy[string] `env:"MULTI_TOKEN"`
+		Host  string           `env:"MULTI_HOST"`
+	}
+	t.Setenv("MULTI_TOKEN", "old")
+	t.Setenv("MULTI_HOST", "localhost")
+
+	var cfg config
+	assert.NoError(t, env.ParseMulti(&cfg))
+	os.Setenv("MULTI_TOKEN", "newer")
+	token, err := cfg.Token.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", token)
+	assert.Equal(t, "localhost", cfg.Host)
+}
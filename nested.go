@@ -0,0 +1,223 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isStructSlice reports whether t is a slice of structs, or of pointers to
+// structs, which are read from indexed variables
+func isStructSlice(t reflect.Type, convs converters) bool {
+	if _, ok := convs[t]; ok || t.Kind() != reflect.Slice {
+		return false
+	}
+	return isStruct(t.Elem(), convs)
+}
+
+// isStructMap reports whether t is a map of structs, or of pointers to
+// structs, which are read from keyed variables
+func isStructMap(t reflect.Type, convs converters) bool {
+	if _, ok := convs[t]; ok || t.Kind() != reflect.Map {
+		return false
+	}
+	return isStruct(t.Elem(), convs)
+}
+
+// isStruct reports whether t is a struct, or a pointer to a struct, whose
+// fields are read one by one rather than converted from a single value
+func isStruct(t reflect.Type, convs converters) bool {
+	if _, ok := convs[t]; ok {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		return isStruct(t.Elem(), convs)
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	return t.Kind() == reflect.Struct && t.NumField() > 0
+}
+
+// newStruct returns a new value of t, a struct or a pointer to a struct,
+// and the struct to parse into it
+func newStruct(t reflect.Type) (reflect.Value, reflect.Value) {
+	if t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem())
+		return v, v.Elem()
+	}
+	v := reflect.New(t).Elem()
+	return v, v
+}
+
+// subKeys returns the distinct segments following `prefix_` in the keys of
+// the Lookuper that are followed by the key of a field of t, the struct
+// element, sorted. E.g. the segments of `UPSTREAM` in `UPSTREAM_0_HOST` and
+// `UPSTREAM_1_PORT` are `0` and `1`, but `UPSTREAM_URL` has none.
+func subKeys(prefix string, t reflect.Type, o *options) ([]string, error) {
+	lister, ok := o.lookuper.(KeyLister)
+	if !ok {
+		return nil, errors.New("Environment variable " + prefix + " is indexed, which requires a Lookuper that lists its keys")
+	}
+	keys := map[string]bool{}
+	fieldKeys(t, "", o, keys, map[reflect.Type]bool{})
+	seen := map[string]bool{}
+	var segments []string
+	for _, key := range lister.Keys() {
+		if !strings.HasPrefix(key, prefix+"_") {
+			continue
+		}
+		parts := strings.SplitN(key[len(prefix)+1:], "_", 2)
+		if len(parts) != 2 || !matchesFieldKey(parts[1], keys) {
+			continue
+		}
+		segment := parts[0]
+		if segment != "" && !seen[segment] {
+			seen[segment] = true
+			segments = append(segments, segment)
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// fieldKeys adds the keys of the fields of t, a struct or a pointer to a
+// struct, to keys, prefixed by prefix. Keys that prefix other variables,
+// such as those of indexed fields, map to true.
+func fieldKeys(t reflect.Type, prefix string, o *options, keys map[string]bool, seen map[reflect.Type]bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		info, err := parseTag(field)
+		if err != nil {
+			// reported when the field is parsed
+			continue
+		}
+		if info.key == "" && len(o.fallbackTags) > 0 {
+			info.key = o.fallbackKey(field)
+		}
+		if info.key == "" {
+			if isStruct(field.Type, o.converters) {
+				fieldKeys(field.Type, o.nestedPrefix(prefix, field.Name, info), o, keys, seen)
+			}
+			continue
+		}
+		key := o.transformKey(prefix + info.key)
+		switch {
+		case isWildcard(key):
+			keys[key[:strings.IndexAny(key, "*?[")]] = true
+		case isStructSlice(field.Type, o.converters) || isStructMap(field.Type, o.converters):
+			keys[key+"_"] = true
+		default:
+			keys[key] = false
+		}
+		if info.featurePrefix != "" {
+			keys[o.transformKey(prefix+info.featurePrefix)] = true
+		}
+		if info.privateKey != "" {
+			keys[o.transformKey(prefix+info.privateKey)] = false
+		}
+	}
+}
+
+// matchesFieldKey reports whether key is one of keys, or is prefixed by one
+// of those that prefix other variables
+func matchesFieldKey(key string, keys map[string]bool) bool {
+	if _, ok := keys[key]; ok {
+		return true
+	}
+	for k, isPrefix := range keys {
+		if isPrefix && strings.HasPrefix(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIndexed fills field, a slice of structs, from the variables
+// `KEY_0_...`, `KEY_1_...` and so on, in increasing order of index
+func parseIndexed(field reflect.Value, info tagInfo, fieldPath string, o *options) error {
+	segments, err := subKeys(info.key, field.Type().Elem(), o)
+	if err != nil {
+		return err
+	}
+	var indexes []int
+	for _, segment := range segments {
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && strconv.Itoa(index) == segment {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		if info.required {
+			o.requiredMissing(info.key)
+			return errors.New("Required environment variable " + info.key + "_0_... is not set")
+		}
+		return nil
+	}
+	sort.Ints(indexes)
+
+	t := field.Type()
+	slice := reflect.MakeSlice(t, len(indexes), len(indexes))
+	for i, index := range indexes {
+		n := strconv.Itoa(index)
+		elem, ref := newStruct(t.Elem())
+		if err := o.enter(ref, fieldPath+"["+n+"]"); err != nil {
+			return err
+		}
+		if err := doParse(ref, fieldPath+"["+n+"].", info.key+"_"+n+"_", o); err != nil {
+			return err
+		}
+		slice.Index(i).Set(elem)
+	}
+	field.Set(slice)
+	return nil
+}
+
+// parseKeyed fills field, a map of structs, from variables such as
+// `KEY_EU_...` and `KEY_US_...`, where the segment following the field's
+// key is the map key
+func parseKeyed(field reflect.Value, info tagInfo, fieldPath string, o *options) error {
+	segments, err := subKeys(info.key, field.Type().Elem(), o)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		if info.required {
+			o.requiredMissing(info.key)
+			return errors.New("Required environment variable " + info.key + "_<key>_... is not set")
+		}
+		return nil
+	}
+
+	t := field.Type()
+	c := o.conversion(info)
+	m := reflect.MakeMap(t)
+	for _, segment := range segments {
+		k, err := convert(info.normalize(segment), t.Key(), c)
+		if err != nil {
+			return err
+		}
+		elem, ref := newStruct(t.Elem())
+		if err := o.enter(ref, fieldPath+"["+segment+"]"); err != nil {
+			return err
+		}
+		if err := doParse(ref, fieldPath+"["+segment+"].", info.key+"_"+segment+"_", o); err != nil {
+			return err
+		}
+		m.SetMapIndex(k, elem)
+	}
+	field.Set(m)
+	return nil
+}
@@ -0,0 +1,116 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type upstream struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT" envDefault:"80"`
+}
+
+func TestIndexedSlice(t *testing.T) {
+	type config struct {
+		Upstreams []upstream  `env:"UPSTREAM"`
+		Backups   []*upstream `env:"BACKUP"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"UPSTREAM_0_HOST":  "a.example.com",
+		"UPSTREAM_0_PORT":  "8080",
+		"UPSTREAM_1_HOST":  "b.example.com",
+		"UPSTREAM_10_HOST": "c.example.com",
+		"UPSTREAM_X_HOST":  "ignored",
+		"BACKUP_0_HOST":    "d.example.com",
+	})))
+	assert.Equal(t, []upstream{
+		{Host: "a.example.com", Port: 8080},
+		{Host: "b.example.com", Port: 80},
+		{Host: "c.example.com", Port: 80},
+	}, cfg.Upstreams)
+	assert.Equal(t, []*upstream{{Host: "d.example.com", Port: 80}}, cfg.Backups)
+}
+
+func TestIndexedSliceErrors(t *testing.T) {
+	type config struct {
+		Upstreams []upstream `env:"UPSTREAM,required"`
+	}
+	cfg := config{}
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{})), "Required environment variable UPSTREAM_0_... is not set")
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"UPSTREAM_0_PORT": "1"})), "Required environment variable UPSTREAM_0_HOST is not set")
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.LookuperFunc(env.Map{}.Lookup))), "Environment variable UPSTREAM is indexed, which requires a Lookuper that lists its keys")
+}
+
+func TestKeyedMap(t *testing.T) {
+	type config struct {
+		Upstreams map[string]upstream  `env:"UPSTREAM"`
+		Backups   map[string]*upstream `env:"BACKUP"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"UPSTREAM_EU_HOST": "eu.example.com",
+		"UPSTREAM_EU_PORT": "8080",
+		"UPSTREAM_US_HOST": "us.example.com",
+		"BACKUP_EU_HOST":   "backup.example.com",
+		// not entries, as HOST and PORT aren't what follows the segment
+		"UPSTREAM_URL":        "https://example.com",
+		"UPSTREAM_TIMEOUT_MS": "500",
+	})))
+	assert.Equal(t, map[string]upstream{
+		"EU": {Host: "eu.example.com", Port: 8080},
+		"US": {Host: "us.example.com", Port: 80},
+	}, cfg.Upstreams)
+	assert.Equal(t, map[string]*upstream{"EU": {Host: "backup.example.com", Port: 80}}, cfg.Backups)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"UPSTREAM_EU_PORT": "1"})), "Required environment variable UPSTREAM_EU_HOST is not set")
+}
+
+func TestKeyedMapNormalization(t *testing.T) {
+	type config struct {
+		Upstreams map[string]upstream `env:"UPSTREAM,lowercase"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"UPSTREAM_EU_HOST": "eu.example.com"})))
+	assert.Equal(t, map[string]upstream{"eu": {Host: "eu.example.com", Port: 80}}, cfg.Upstreams)
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	type config struct {
+		Server struct {
+			Port int `env:"PORT"`
+			TLS  *struct {
+				Cert string `env:"CERT"`
+			} `envPrefix:"TLS_"`
+		} `envPrefix:"SERVER_"`
+		Cache struct {
+			URL string `env:"URL"`
+		}
+		Upstream upstream `envPrefix:"UPSTREAM_"`
+	}
+	cfg := config{}
+	cfg.Server.TLS = &struct {
+		Cert string `env:"CERT"`
+	}{}
+	err := env.Parse(&cfg, env.WithDerivedPrefixes(), env.WithParallelLookups(2), env.WithLookuper(env.Map{
+		"SERVER_PORT":     "8080",
+		"SERVER_TLS_CERT": "cert.pem",
+		"CACHE_URL":       "redis://",
+		"UPSTREAM_HOST":   "example.com",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, "cert.pem", cfg.Server.TLS.Cert)
+	assert.Equal(t, "redis://", cfg.Cache.URL)
+	assert.Equal(t, upstream{Host: "example.com", Port: 80}, cfg.Upstream)
+
+	vars, err := env.Describe(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERVER_TLS_CERT", vars[1].Key)
+
+	err = env.Parse(&config{}, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Required environment variable UPSTREAM_HOST is not set"), err)
+}
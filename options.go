@@ -0,0 +1,247 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Option customizes the behavior of `Parse` and friends
+type Option func(*options)
+
+type options struct {
+	ctx      context.Context
+	funcMap  CustomParsers
+	lookuper Lookuper
+	// live is the Lookuper replaced by WithSnapshot, still read by Lazy and
+	// Refreshing fields and by Drifted
+	live      Lookuper
+	retry     *RetryPolicy
+	metrics   Metrics
+	tracer    Tracer
+	audit     []string
+	dupCheck  bool
+	tagsCheck bool
+	trim      bool
+	relaxed   bool
+	extended  bool
+	base      int
+	prefix    string
+	derive    bool
+
+	// keys that can't use their default, and secrets, see
+	// WithForbidDefaults
+	forbidDefaults map[string]bool
+
+	// commands that `envSource` tags may run, see WithCommands
+	commands map[string]bool
+
+	// receives the audit events of secret lookups, see WithSecretAccessLog
+	accessActor string
+	accessLog   SecretAccessLog
+
+	// tags keys are derived from when there's no env tag, see
+	// WithFallbackTag
+	fallbackTags []string
+
+	// named sources, in the order they were added, and the order sources
+	// are consulted in, see WithPriority
+	sources     map[string]Lookuper
+	sourceNames []string
+	// retry policies of the sources given their own, see WithSource
+	sourceRetry map[string]*RetryPolicy
+	priority    []string
+
+	// rewrite keys before lookups, see WithKeyTransform
+	transforms []KeyTransform
+
+	// funcMap merged with the built-in converters, see newConverters
+	converters converters
+
+	// paths of the fields to parse, see WithFields, and whether they were
+	// found
+	only map[string]bool
+
+	// decrypts `enc:v1:` values, see WithDecrypter
+	decrypter Decrypter
+
+	// configures fields instead of their tags, see WithSpec
+	spec     *Spec
+	specUsed map[string]bool
+
+	// asks for missing required variables, see WithPrompter
+	prompter Prompter
+
+	// concurrent lookups, see WithParallelLookups, and their results
+	workers    int
+	prefetched map[string]lookupResult
+
+	// whether Environ exports secrets
+	exportSecrets bool
+
+	// key of the HMAC of Fingerprint, see WithFingerprintKey
+	fingerprintKey []byte
+
+	// report filled by Parse, see WithReport
+	report *Report
+
+	// warnings found by Parse, see WithResult
+	result *Result
+
+	// the field being explained, see Explain
+	explain *explanation
+
+	// values of the variables read, see WithDriftDetection
+	observed map[string]observation
+
+	// keys read by the struct, mapped to the path of the field reading them
+	consumed map[string]string
+
+	// how deep structs may be nested, and the structs being parsed, mapped
+	// to the path of their field, see enter
+	maxDepth int
+	visiting map[uintptr]string
+
+	// counters for the parse span
+	fields int
+	errors int
+
+	// required variables found missing, in the order of their fields
+	missing []string
+
+	// path and key of the field being parsed, see safeParse
+	currentPath string
+	currentKey  string
+}
+
+func newOptions(ctx context.Context, opts []Option) *options {
+	o := &options{
+		ctx:      ctx,
+		funcMap:  CustomParsers{},
+		lookuper: osLookuper{},
+		base:     10,
+		consumed: map[string]string{},
+		maxDepth: defaultMaxDepth,
+		visiting: map[uintptr]string{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.converters = newConverters(o.funcMap)
+	return o
+}
+
+// WithFuncs adds custom parsers, see `ParseWithFuncs`
+func WithFuncs(funcMap CustomParsers) Option {
+	return func(o *options) {
+		for t, f := range funcMap {
+			o.funcMap[t] = f
+		}
+	}
+}
+
+// WithTrim strips surrounding whitespace and matching quotes from all values,
+// as if every field had the `trim` option
+func WithTrim() Option {
+	return func(o *options) {
+		o.trim = true
+	}
+}
+
+// WithRelaxedBool accepts yes/no, on/off and enabled/disabled for all bool
+// fields, as if every field had the `relaxedBool` option
+func WithRelaxedBool() Option {
+	return func(o *options) {
+		o.relaxed = true
+	}
+}
+
+// WithExtendedDurations accepts days and weeks, such as `1.5d` or `2w`, for
+// all duration fields, as if every field had the `extendedDuration` option
+func WithExtendedDurations() Option {
+	return func(o *options) {
+		o.extended = true
+	}
+}
+
+// WithIntBase parses integer fields in the given base, as if every field
+// had the `envBase` tag. Base 0 accepts `1_000_000`, `0x1F`, `0o755` and
+// other Go integer literals.
+func WithIntBase(base int) Option {
+	return func(o *options) {
+		o.base = base
+	}
+}
+
+// WithLookuper sets where the values are read from instead of the process
+// environment
+func WithLookuper(l Lookuper) Option {
+	return func(o *options) {
+		o.lookuper, o.live = l, nil
+	}
+}
+
+// lookup returns the value of key, decrypted. Remote lookups are canceled
+// after timeout, if positive.
+func (o *options) lookup(key string, timeout time.Duration) (string, bool, error) {
+	value, ok, err := o.lookupRaw(key, timeout)
+	if err == nil {
+		o.observe(key, value, ok)
+	}
+	if err != nil || !ok {
+		return value, ok, err
+	}
+	value, err = o.decrypt(key, value)
+	return value, ok, err
+}
+
+// liveLookuper returns the Lookuper reading the current values, rather than
+// the snapshot taken by WithSnapshot
+func (o *options) liveLookuper() Lookuper {
+	if o.live != nil {
+		return o.live
+	}
+	return o.lookuper
+}
+
+func (o *options) lookupRaw(key string, timeout time.Duration) (string, bool, error) {
+	if r, ok := o.prefetched[key]; ok {
+		if r.panic != nil {
+			panic(r.panic)
+		}
+		return r.value, r.ok, r.err
+	}
+	return o.lookupIn(o.lookuper, o.retry, key, timeout)
+}
+
+// lookupIn returns the value of key in the source src, retrying failed
+// lookups according to retry, if not nil
+func (o *options) lookupIn(src Lookuper, retry *RetryPolicy, key string, timeout time.Duration) (string, bool, error) {
+	if l, ok := src.(ContextLookuper); ok {
+		defer o.lookupLatency(key, time.Now())
+		ctx, span := o.startSpan("env.Lookup")
+		span.SetAttribute("env.key", key)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		var (
+			value string
+			ok    bool
+			err   error
+		)
+		if retry != nil {
+			value, ok, err = retry.lookup(ctx, l, key)
+		} else {
+			value, ok, err = l.LookupContext(ctx, key)
+		}
+		if err != nil && ctx.Err() == context.DeadlineExceeded && o.ctx.Err() == nil {
+			err = errors.New("Lookup of " + key + " timed out after " + timeout.String())
+		}
+		span.End(err)
+		return value, ok, err
+	}
+	value, ok := src.Lookup(key)
+	return value, ok, nil
+}
@@ -0,0 +1,57 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// reversedMap lists its keys in reverse order
+type reversedMap struct {
+	env.Map
+}
+
+func (m reversedMap) Keys() []string {
+	keys := m.Map.Keys()
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+func TestErrorOrder(t *testing.T) {
+	type database struct {
+		Host string `env:"APP_DB_HOST,required"`
+		Port int    `env:"APP_DB_PORT"`
+	}
+	type config struct {
+		Z        int `env:"APP_Z"`
+		Database database
+		A        int               `env:"APP_A"`
+		Labels   map[string]int    `env:"APP_LABEL_*"`
+		Limits   map[string]string `env:"APP_LIMITS,required"`
+	}
+	lookuper := reversedMap{env.Map{
+		"APP_Z":       "z",
+		"APP_DB_PORT": "p",
+		"APP_A":       "a",
+		"APP_LABEL_X": "x",
+		"APP_UNKNOWN": "1",
+		"APP_TYPO":    "1",
+	}}
+
+	expected := `strconv.ParseInt: parsing "z": invalid syntax. ` +
+		"Required environment variable APP_DB_HOST is not set. " +
+		`strconv.ParseInt: parsing "p": invalid syntax. ` +
+		`strconv.ParseInt: parsing "a": invalid syntax. ` +
+		`strconv.ParseInt: parsing "x": invalid syntax. ` +
+		"Required environment variable APP_LIMITS is not set. " +
+		"Unknown environment variable APP_TYPO. " +
+		"Unknown environment variable APP_UNKNOWN. " +
+		"2 required variables missing: APP_DB_HOST, APP_LIMITS"
+	for i := 0; i < 20; i++ {
+		err := env.Parse(&config{}, env.WithLookuper(lookuper), env.WithNamespaceAudit("APP_"))
+		assert.EqualError(t, err, expected)
+	}
+}
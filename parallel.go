@@ -0,0 +1,106 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithParallelLookups resolves the variables of a ContextLookuper, such as a
+// remote secret store, with up to workers concurrent lookups before parsing,
+// instead of one after the other. The Lookuper, and the Metrics and Tracer
+// if any, must be safe for concurrent use. Variables of lists and maps of
+// structs and of patterns are still resolved one by one.
+func WithParallelLookups(workers int) Option {
+	return func(o *options) {
+		o.workers = workers
+	}
+}
+
+// lookupResult is the result of a lookup made ahead of parsing
+type lookupResult struct {
+	value string
+	ok    bool
+	err   error
+	// panic is the value of a panic of the lookup, raised again when the
+	// field is parsed so that safeParse reports it as a FieldError
+	panic interface{}
+}
+
+// keyTimeout is a variable to resolve and the timeout of its lookup
+type keyTimeout struct {
+	key     string
+	timeout time.Duration
+}
+
+// prefetch resolves the variables of the fields of ref concurrently, if
+// enabled, storing the results for lookupRaw
+func (o *options) prefetch(ref reflect.Value) {
+	if _, ok := o.lookuper.(ContextLookuper); !ok || o.workers < 2 {
+		return
+	}
+	keys := o.collectKeys(ref, "", o.prefix, nil)
+	results := make([]lookupResult, len(keys))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers && w < len(keys); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				o.prefetchOne(keys[i], &results[i])
+			}
+		}()
+	}
+	for i := range keys {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	o.prefetched = make(map[string]lookupResult, len(keys))
+	for i, k := range keys {
+		o.prefetched[k.key] = results[i]
+	}
+}
+
+// prefetchOne looks up k into r, recovering a panic of the Lookuper
+func (o *options) prefetchOne(k keyTimeout, r *lookupResult) {
+	defer func() {
+		r.panic = recover()
+	}()
+	r.value, r.ok, r.err = o.lookupRaw(k.key, k.timeout)
+}
+
+// collectKeys returns the variables doParse reads from the Lookuper one by
+// one for ref. The fields are read as by doParse, except that Lazy and
+// Refreshing fields, resolved when read, and fields with their own sources
+// are left out.
+func (o *options) collectKeys(ref reflect.Value, path, prefix string, keys []keyTimeout) []keyTimeout {
+	// doParse reports structs nested too deep, including cycles
+	if strings.Count(path, ".") > o.maxDepth {
+		return keys
+	}
+	refType := ref.Type()
+	for i := 0; i < refType.NumField(); i++ {
+		field, fieldPath := ref.Field(i), path+refType.Field(i).Name
+		info, err := o.prefixedInfo(refType.Field(i), fieldPath, prefix)
+		if err != nil || !o.selected(fieldPath) {
+			continue
+		}
+		switch o.kindOf(field, info) {
+		case kindStructPtr:
+			if field.Elem().Kind() == reflect.Struct {
+				keys = o.collectKeys(field.Elem(), fieldPath+".", o.nestedPrefix(prefix, refType.Field(i).Name, info), keys)
+			}
+		case kindStruct:
+			keys = o.collectKeys(field, fieldPath+".", o.nestedPrefix(prefix, refType.Field(i).Name, info), keys)
+		case kindValue, kindImplementation, kindFeatures, kindKeyPair:
+			if info.key != "" && info.command == nil && info.priority == nil {
+				keys = append(keys, keyTimeout{key: info.key, timeout: info.timeout})
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrentLookuper records how many lookups run at the same time
+type concurrentLookuper struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	keys    []string
+}
+
+func (l *concurrentLookuper) Lookup(key string) (string, bool) {
+	return "", false
+}
+
+func (l *concurrentLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	l.mu.Lock()
+	l.keys = append(l.keys, key)
+	l.running++
+	if l.running > l.max {
+		l.max = l.running
+	}
+	l.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	l.mu.Lock()
+	l.running--
+	l.mu.Unlock()
+	return key + "-value", true, nil
+}
+
+func TestParallelLookups(t *testing.T) {
+	type inner struct {
+		D string `env:"D"`
+	}
+	type config struct {
+		A     string `env:"A"`
+		B     string `env:"B"`
+		C     string `env:"C"`
+		Inner *inner
+	}
+	l := &concurrentLookuper{}
+	cfg := config{Inner: &inner{}}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l), env.WithParallelLookups(3)))
+	assert.Equal(t, config{A: "A-value", B: "B-value", C: "C-value", Inner: &inner{D: "D-value"}}, cfg)
+	assert.Equal(t, 3, l.max)
+}
+
+// panickingLookuper panics when looking up PORT
+type panickingLookuper struct {
+	concurrentLookuper
+}
+
+func (l *panickingLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if key == "PORT" {
+		panic("lookup of PORT")
+	}
+	return l.concurrentLookuper.LookupContext(ctx, key)
+}
+
+func TestParallelLookupsPanic(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port string `env:"PORT"`
+	}
+	err := env.Parse(&config{}, env.WithLookuper(&panickingLookuper{}), env.WithParallelLookups(2))
+	var fieldErr *env.FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.EqualError(t, err, "Field Port (PORT) couldn't be parsed: lookup of PORT")
+}
+
+func TestParallelLookupsSkipLazyFields(t *testing.T) {
+	type config struct {
+		A       string                 `env:"A"`
+		Token   env.Lazy[string]       `env:"TOKEN"`
+		Level   env.Refreshing[string] `env:"LEVEL"`
+		Timeout string                 `env:"TIMEOUT" envDefault:"5s" envPriority:"default,env"`
+	}
+	l := &concurrentLookuper{}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l), env.WithParallelLookups(3)))
+	// Refreshing fields are resolved once by Parse, after the prefetch
+	assert.Equal(t, []string{"A", "LEVEL"}, l.keys)
+	assert.Equal(t, "5s", cfg.Timeout)
+}
@@ -0,0 +1,130 @@
+package env
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+)
+
+// PEMCertificate is a chain of X.509 certificates in PEM format, validated
+// by Parse. Values may be single-line, with `\n` escape sequences.
+type PEMCertificate struct {
+	// PEM is the value the certificates were parsed from
+	PEM          []byte
+	Certificates []*x509.Certificate
+}
+
+// PEMPrivateKey is a private key in PEM format, PKCS #1, PKCS #8 or SEC 1
+// encoded, validated by Parse. Values may be single-line, with `\n` escape
+// sequences. Fields of this type are always secret.
+type PEMPrivateKey struct {
+	// PEM is the value the key was parsed from
+	PEM []byte
+	// Key is a *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey
+	Key crypto.PrivateKey
+}
+
+var (
+	pemPrivateKeyType = reflect.TypeOf(PEMPrivateKey{})
+	keyPairType       = reflect.TypeOf(tls.Certificate{})
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *PEMCertificate) UnmarshalText(text []byte) error {
+	data := []byte(unescapeNewlines(string(text)))
+	var certs []*x509.Certificate
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return errors.New("Invalid PEM certificate: unexpected " + block.Type + " block")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return errors.New("Invalid PEM certificate: " + err.Error())
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return errors.New("Invalid PEM certificate: no CERTIFICATE block found")
+	}
+	*c = PEMCertificate{PEM: data, Certificates: certs}
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (k *PEMPrivateKey) UnmarshalText(text []byte) error {
+	data := []byte(unescapeNewlines(string(text)))
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("Invalid PEM private key: no PEM block found")
+	}
+	var (
+		key crypto.PrivateKey
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return errors.New("Invalid PEM private key: unexpected " + block.Type + " block")
+	}
+	if err != nil {
+		return errors.New("Invalid PEM private key: " + err.Error())
+	}
+	*k = PEMPrivateKey{PEM: data, Key: key}
+	return nil
+}
+
+// String returns a redacted marker, so keys don't leak into logs
+func (k PEMPrivateKey) String() string {
+	return redacted
+}
+
+// isSecretType reports whether the values of type t are always secret
+func isSecretType(t reflect.Type) bool {
+	return t == pemPrivateKeyType || t == keyPairType || t == secretType
+}
+
+// parseKeyPair sets field, a tls.Certificate, from the certificate chain in
+// info.key and the private key in `envPrivateKey`, returning the source of
+// the certificate
+func parseKeyPair(field reflect.Value, info tagInfo, fieldPath string, o *options) (string, error) {
+	if info.privateKey == "" {
+		return SourceUnset, errors.New("Field " + fieldPath + " is a tls.Certificate, which requires the envPrivateKey tag")
+	}
+	cert, source, err := get(info, fieldPath, o)
+	if err != nil {
+		return source, err
+	}
+	keyInfo := info
+	keyInfo.key, keyInfo.defaultValue = info.privateKey, ""
+	key, _, err := get(keyInfo, fieldPath, o)
+	if err != nil {
+		return source, err
+	}
+	switch {
+	case cert == "" && key == "":
+		return source, nil
+	case cert == "":
+		return source, errors.New("Environment variable " + info.key + " is required with " + info.privateKey)
+	case key == "":
+		return source, errors.New("Environment variable " + info.privateKey + " is required with " + info.key)
+	}
+	pair, err := tls.X509KeyPair([]byte(unescapeNewlines(cert)), []byte(unescapeNewlines(key)))
+	if err != nil {
+		return source, errors.New("Invalid key pair " + info.key + " and " + info.privateKey + ": " + err.Error())
+	}
+	field.Set(reflect.ValueOf(pair))
+	return source, nil
+}
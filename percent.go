@@ -0,0 +1,43 @@
+package env
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Percent is a ratio in [0, 1], such as a sampling rate or a resource
+// threshold. It is parsed from `75%`, `0.75` or `75`: values with a percent
+// sign and values greater than 1 are percentages, other values are ratios.
+// Bare numbers are therefore ambiguous around 1: `1` is 100% but `2` is 2%,
+// so percentages of 1% or less must be written with a percent sign.
+type Percent float64
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (p *Percent) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return errors.New("Invalid percentage " + string(text))
+	}
+	if strings.HasSuffix(s, "%") || v > 1 {
+		v /= 100
+	}
+	if v < 0 || v > 1 {
+		return errors.New("Invalid percentage " + string(text) + ", expected 0% to 100%")
+	}
+	*p = Percent(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (p Percent) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// String returns the percentage, e.g. `75%`
+func (p Percent) String() string {
+	// rounded to hide the error of the division by 100
+	return strconv.FormatFloat(math.Round(float64(p)*1e11)/1e9, 'f', -1, 64) + "%"
+}
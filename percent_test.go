@@ -0,0 +1,44 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercent(t *testing.T) {
+	type config struct {
+		Sampling  env.Percent   `env:"SAMPLING"`
+		Threshold env.Percent   `env:"THRESHOLD"`
+		Ratio     env.Percent   `env:"RATIO"`
+		Steps     []env.Percent `env:"STEPS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"SAMPLING":  "75%",
+		"THRESHOLD": "90",
+		"RATIO":     "0.25",
+		"STEPS":     "0,1,50%",
+	})))
+	assert.Equal(t, config{Sampling: 0.75, Threshold: 0.9, Ratio: 0.25, Steps: []env.Percent{0, 1, 0.5}}, cfg)
+	assert.Equal(t, "75%", cfg.Sampling.String())
+	assert.Equal(t, "90%", cfg.Threshold.String())
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"SAMPLING": "150%"}))
+	assert.Equal(t, errors.New("Invalid percentage 150%, expected 0% to 100%"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"RATIO": "-0.5"}))
+	assert.Equal(t, errors.New("Invalid percentage -0.5, expected 0% to 100%"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"RATIO": "half"}))
+	assert.Equal(t, errors.New("Invalid percentage half"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"RATIO": "NaN"}))
+	assert.Equal(t, errors.New("Invalid percentage NaN"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"RATIO": "-Inf%"}))
+	assert.Equal(t, errors.New("Invalid percentage -Inf%"), err)
+
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"SAMPLING": "1", "THRESHOLD": "2", "RATIO": "1%"})))
+	assert.Equal(t, "100%", cfg.Sampling.String())
+	assert.Equal(t, "2%", cfg.Threshold.String())
+	assert.Equal(t, "1%", cfg.Ratio.String())
+}
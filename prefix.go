@@ -0,0 +1,89 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// WithPrefix prepends prefix to the keys of all fields, so that several
+// copies of the same struct can be loaded from namespaced variables, e.g.
+// `PRIMARY_DB_HOST` and `REPLICA_DB_HOST` for a struct reading `DB_HOST`
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithDerivedPrefixes prefixes the keys of nested structs with the name of
+// the field holding them, in upper snake case: the fields of `ReadReplica
+// *dbConfig` read `READ_REPLICA_DB_HOST`... The `envPrefix` tag sets another
+// prefix and `envInline:"true"` keeps the keys of the nested struct as is,
+// which is the default for embedded structs; `envInline:"false"` gives them
+// a prefix derived from their type name.
+func WithDerivedPrefixes() Option {
+	return func(o *options) {
+		o.derive = true
+	}
+}
+
+// WithFallbackTag derives the keys of fields without an `env` tag from
+// another tag, such as `json`, in upper snake case: `json:"dbHost"` reads
+// `DB_HOST`. It eases adopting env for structs already annotated for config
+// files. It can be given several times, the first tag found is used.
+func WithFallbackTag(tag string) Option {
+	return func(o *options) {
+		o.fallbackTags = append(o.fallbackTags, tag)
+	}
+}
+
+// fallbackKey returns the key of field derived from the fallback tags, if
+// it has no env tag. Nested structs keep reading their fields one by one.
+func (o *options) fallbackKey(field reflect.StructField) string {
+	if _, ok := field.Tag.Lookup("env"); ok || isStruct(field.Type, o.converters) {
+		return ""
+	}
+	for _, tag := range o.fallbackTags {
+		name, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		name = strings.Split(name, ",")[0]
+		if name == "-" || name == "" {
+			return ""
+		}
+		return upperSnake(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	}
+	return ""
+}
+
+// nestedPrefix returns the prefix of the keys of the struct nested in the
+// field named name, whose keys are prefixed by prefix
+func (o *options) nestedPrefix(prefix, name string, info tagInfo) string {
+	switch {
+	case info.inline:
+		return prefix
+	case info.prefix != "":
+		return prefix + info.prefix
+	case o.derive:
+		return prefix + upperSnake(name) + "_"
+	}
+	return prefix
+}
+
+// upperSnake converts a Go identifier such as `TLSConfig` to `TLS_CONFIG`
+func upperSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
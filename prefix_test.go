@@ -0,0 +1,134 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWithPrefix(t *testing.T) {
+	l := env.WithLookuper(env.Map{
+		"PRIMARY_HOST": "db1",
+		"PRIMARY_PORT": "5432",
+		"REPLICA_HOST": "db2",
+	})
+	primary, replica := upstream{}, upstream{}
+	assert.NoError(t, env.ParseWithPrefix(&primary, "PRIMARY_", l))
+	assert.NoError(t, env.ParseWithPrefix(&replica, "REPLICA_", l))
+	assert.Equal(t, upstream{Host: "db1", Port: 5432}, primary)
+	assert.Equal(t, upstream{Host: "db2", Port: 80}, replica)
+
+	err := env.ParseWithPrefix(&upstream{}, "STANDBY_", l)
+	assert.Equal(t, errors.New("Required environment variable STANDBY_HOST is not set"), err)
+}
+
+func TestWithPrefixNested(t *testing.T) {
+	type config struct {
+		Upstream *upstream
+	}
+	cfg := config{Upstream: &upstream{}}
+	err := env.Parse(&cfg, env.WithPrefix("APP_"), env.WithParallelLookups(2), env.WithLookuper(env.Map{
+		"APP_HOST": "example.com",
+		"HOST":     "ignored",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.Upstream.Host)
+}
+
+func TestNestedPrefixes(t *testing.T) {
+	type config struct {
+		Primary     *upstream `envPrefix:"DB_"`
+		ReadReplica *upstream
+		Cache       *upstream `envInline:"true"`
+	}
+	l := env.WithLookuper(env.Map{
+		"DB_HOST":           "db1",
+		"READ_REPLICA_HOST": "db2",
+		"HOST":              "cache",
+	})
+
+	cfg := config{Primary: &upstream{}, ReadReplica: &upstream{}, Cache: &upstream{}}
+	assert.NoError(t, env.Parse(&cfg, l, env.WithDerivedPrefixes()))
+	assert.Equal(t, "db1", cfg.Primary.Host)
+	assert.Equal(t, "db2", cfg.ReadReplica.Host)
+	assert.Equal(t, "cache", cfg.Cache.Host)
+
+	cfg = config{Primary: &upstream{}, ReadReplica: &upstream{}, Cache: &upstream{}}
+	assert.NoError(t, env.Parse(&cfg, l))
+	assert.Equal(t, "db1", cfg.Primary.Host)
+	assert.Equal(t, "cache", cfg.ReadReplica.Host)
+}
+
+func TestEnvInlineErrors(t *testing.T) {
+	type both struct {
+		Upstream *upstream `envPrefix:"DB_" envInline:"true"`
+	}
+	err := env.Parse(&both{Upstream: &upstream{}})
+	assert.Equal(t, errors.New("Field Upstream can't have both envPrefix and envInline"), err)
+
+	type invalid struct {
+		Upstream *upstream `envInline:"yes"`
+	}
+	err = env.Parse(&invalid{Upstream: &upstream{}})
+	assert.Equal(t, errors.New("Invalid envInline yes, expected true or false"), err)
+}
+
+func TestFallbackTag(t *testing.T) {
+	type config struct {
+		DBHost   string    `json:"dbHost"`
+		Port     int       `json:"port,omitempty" env:"APP_PORT"`
+		LogLevel string    `json:"log-level"`
+		Ignored  string    `json:"-"`
+		Upstream *upstream `json:"upstream" envPrefix:"UP_"`
+	}
+	l := env.WithLookuper(env.Map{
+		"DB_HOST":   "db",
+		"APP_PORT":  "8080",
+		"PORT":      "80",
+		"LOG_LEVEL": "debug",
+		"IGNORED":   "x",
+		"UP_HOST":   "up",
+	})
+	cfg := config{Upstream: &upstream{}}
+	assert.NoError(t, env.Parse(&cfg, l, env.WithFallbackTag("json")))
+	assert.Equal(t, config{DBHost: "db", Port: 8080, LogLevel: "debug", Upstream: &upstream{Host: "up", Port: 80}}, cfg)
+
+	cfg = config{Upstream: &upstream{}}
+	assert.NoError(t, env.Parse(&cfg, l))
+	assert.Equal(t, "", cfg.DBHost)
+}
+
+type Common struct {
+	LogLevel string `env:"LOG_LEVEL"`
+}
+
+type Limits struct {
+	Rate int `env:"RATE"`
+}
+
+func TestEmbeddedPrefixes(t *testing.T) {
+	type config struct {
+		Common
+		Limits   `envInline:"false"`
+		Upstream *upstream
+	}
+	l := env.WithLookuper(env.Map{
+		"APP_LOG_LEVEL":     "debug",
+		"APP_LIMITS_RATE":   "10",
+		"APP_UPSTREAM_HOST": "up",
+	})
+	cfg := config{Upstream: &upstream{}}
+	assert.NoError(t, env.Parse(&cfg, l, env.WithPrefix("APP_"), env.WithDerivedPrefixes()))
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, 10, cfg.Rate)
+	assert.Equal(t, "up", cfg.Upstream.Host)
+
+	type prefixed struct {
+		Common `envPrefix:"SHARED_"`
+	}
+	p := prefixed{}
+	assert.NoError(t, env.Parse(&p, env.WithLookuper(env.Map{"SHARED_LOG_LEVEL": "warn"})))
+	assert.Equal(t, "warn", p.LogLevel)
+}
@@ -0,0 +1,205 @@
+// Package cron provides a Schedule type for cron expressions, such as
+// `BACKUP_CRON="0 3 * * *"`, validated at startup so schedulers fail fast on
+// bad values. Importing it registers its parser with env:
+//
+//	import _ "github.com/caarlos0/env/presets/cron"
+package cron
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+)
+
+// Schedule is a standard 5-field cron expression: minute, hour, day of
+// month, month and day of week
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// Type is the `reflect.Type` of Schedule
+var Type = reflect.TypeOf(Schedule{})
+
+func init() {
+	env.RegisterParser(Type, Func)
+}
+
+// Func is a parser for Schedule to be used with `env.ParseWithFuncs()`. It
+// is registered with `env.RegisterParser` when the package is imported.
+func Func(v string) (interface{}, error) {
+	return Parse(v)
+}
+
+// field describes one of the fields of an expression
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression such as `*/15 9-17 * * mon-fri`. Fields
+// accept `*`, values, ranges, steps and lists; months and days of week also
+// accept their 3-letter English names. The @yearly, @monthly, @weekly,
+// @daily and @hourly descriptors are accepted too.
+func Parse(s string) (Schedule, error) {
+	expr := strings.TrimSpace(s)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Schedule{}, errors.New("Invalid cron expression " + strconv.Quote(s) + ": expected 5 fields, got " + strconv.Itoa(len(parts)))
+	}
+	sched := Schedule{expr: s}
+	sets := []*uint64{&sched.minute, &sched.hour, &sched.dom, &sched.month, &sched.dow}
+	for i, part := range parts {
+		set, err := fields[i].parse(part)
+		if err != nil {
+			return Schedule{}, errors.New("Invalid cron expression " + strconv.Quote(s) + ": " + err.Error())
+		}
+		*sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+	// as in cron, fields starting with * aren't restrictions, even with steps
+	sched.domRestricted = !strings.HasPrefix(parts[2], "*")
+	sched.dowRestricted = !strings.HasPrefix(parts[4], "*")
+	return sched, nil
+}
+
+// parse returns the set of values matched by s, as a bitset
+func (f field) parse(s string) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		if item == "" {
+			return 0, errors.New("empty item in " + f.name + " " + s)
+		}
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, errors.New("invalid step in " + f.name + " " + item)
+			}
+			rng, step = item[:i], n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, errors.New("invalid range in " + f.name + " " + item)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// value parses a value of the field, or its name
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.New(f.name + " " + s + " out of range " + strconv.Itoa(f.min) + "-" + strconv.Itoa(f.max))
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t matched by the schedule, in
+// t's location, or the zero time if there is none within 5 years
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.minute == 0 {
+		return time.Time{}
+	}
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t is matched. As in cron, when both
+// the day of month and the day of week are restricted, either can match.
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// String returns the expression the schedule was parsed from
+func (s Schedule) String() string {
+	return s.expr
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.expr), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Schedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
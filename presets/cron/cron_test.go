@@ -0,0 +1,92 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/presets/cron"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, time.January, 10, 10, 30, 0, 0, time.UTC)
+	for expr, want := range map[string]time.Time{
+		"* * * * *":             time.Date(2024, time.January, 10, 10, 31, 0, 0, time.UTC),
+		"0 3 * * *":             time.Date(2024, time.January, 11, 3, 0, 0, 0, time.UTC),
+		"*/15 * * * *":          time.Date(2024, time.January, 10, 10, 45, 0, 0, time.UTC),
+		"5/20 * * * *":          time.Date(2024, time.January, 10, 10, 45, 0, 0, time.UTC),
+		"0 9-17 * * *":          time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC),
+		"0 8-10/2 * * *":        time.Date(2024, time.January, 11, 8, 0, 0, 0, time.UTC),
+		"0,45 * * * *":          time.Date(2024, time.January, 10, 10, 45, 0, 0, time.UTC),
+		"0 0 1 * *":             time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 * * mon-fri":       time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":             time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+		"0 0 * * SUN":           time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+		"0 0 * feb *":           time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":            time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * fri":          time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC),
+		"0 0 */10 * *":          time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC),
+		"@hourly":               time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC),
+		"@daily":                time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC),
+		"@weekly":               time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+		"@monthly":              time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":               time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"  30 10 10 jan wed   ": time.Date(2024, time.January, 17, 10, 30, 0, 0, time.UTC),
+		"0 0 30 2 *":            {},
+	} {
+		sched, err := cron.Parse(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, want, sched.Next(from), expr)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for expr, want := range map[string]string{
+		"":              `Invalid cron expression "": expected 5 fields, got 0`,
+		"* * * *":       `Invalid cron expression "* * * *": expected 5 fields, got 4`,
+		"61 * * * *":    `Invalid cron expression "61 * * * *": minute 61 out of range 0-59`,
+		"* 24 * * *":    `Invalid cron expression "* 24 * * *": hour 24 out of range 0-23`,
+		"* * 0 * *":     `Invalid cron expression "* * 0 * *": day of month 0 out of range 1-31`,
+		"* * * foo *":   `Invalid cron expression "* * * foo *": month foo out of range 1-12`,
+		"* * * * 8":     `Invalid cron expression "* * * * 8": day of week 8 out of range 0-7`,
+		"*/0 * * * *":   `Invalid cron expression "*/0 * * * *": invalid step in minute */0`,
+		"*/x * * * *":   `Invalid cron expression "*/x * * * *": invalid step in minute */x`,
+		"* 17-9 * * *":  `Invalid cron expression "* 17-9 * * *": invalid range in hour 17-9`,
+		"@every 1h":     `Invalid cron expression "@every 1h": expected 5 fields, got 2`,
+		"1,,2 * * * *":  `Invalid cron expression "1,,2 * * * *": empty item in minute 1,,2`,
+		"* * * * mon-x": `Invalid cron expression "* * * * mon-x": day of week x out of range 0-7`,
+	} {
+		_, err := cron.Parse(expr)
+		assert.EqualError(t, err, want, expr)
+	}
+}
+
+func TestText(t *testing.T) {
+	sched, err := cron.Parse("0 3 * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", sched.String())
+	text, err := sched.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", string(text))
+
+	var parsed cron.Schedule
+	assert.NoError(t, parsed.UnmarshalText(text))
+	assert.Equal(t, sched, parsed)
+	assert.Error(t, parsed.UnmarshalText([]byte("0 3 * *")))
+}
+
+func TestParse(t *testing.T) {
+	type config struct {
+		Backup  cron.Schedule   `env:"BACKUP_CRON"`
+		Reports []cron.Schedule `env:"REPORTS_CRON" envSeparator:";"`
+	}
+	var cfg config
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"BACKUP_CRON": "0 3 * * *", "REPORTS_CRON": "@daily;0 12 * * mon"})))
+	assert.Equal(t, "0 3 * * *", cfg.Backup.String())
+	assert.Len(t, cfg.Reports, 2)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"BACKUP_CRON": "61 * * * *"}))
+	assert.EqualError(t, err, `BACKUP_CRON: Invalid cron expression "61 * * * *": minute 61 out of range 0-59`)
+}
@@ -0,0 +1,33 @@
+// Package language registers a parser for golang.org/x/text/language.Tag
+// with env, so that locales such as `DEFAULT_LOCALE=pt-BR` are validated at
+// startup against the registry of known subtags:
+//
+//	import _ "github.com/caarlos0/env/presets/language"
+//
+// `env.LanguageTag` only checks the syntax of tags, without this dependency.
+package language
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/caarlos0/env"
+	"golang.org/x/text/language"
+)
+
+// Type is the `reflect.Type` of language.Tag
+var Type = reflect.TypeOf(language.Tag{})
+
+func init() {
+	env.RegisterParser(Type, Func)
+}
+
+// Func is a parser for language.Tag to be used with `env.ParseWithFuncs()`.
+// It is registered with `env.RegisterParser` when the package is imported.
+func Func(v string) (interface{}, error) {
+	tag, err := language.Parse(v)
+	if err != nil {
+		return nil, errors.New("Invalid language tag " + v + ": " + err.Error())
+	}
+	return tag, nil
+}
@@ -0,0 +1,69 @@
+// Package uuid provides a UUID type for ID-typed config (tenant IDs, client
+// IDs...) whose format is validated at startup. Importing it registers its
+// parser with env:
+//
+//	import _ "github.com/caarlos0/env/presets/uuid"
+//
+// Other UUID types, such as github.com/google/uuid's, implement
+// encoding.TextUnmarshaler and are supported by env without this package.
+package uuid
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/caarlos0/env"
+)
+
+// UUID is a RFC 4122 UUID
+type UUID [16]byte
+
+// Type is the `reflect.Type` of UUID
+var Type = reflect.TypeOf(UUID{})
+
+func init() {
+	env.RegisterParser(Type, Func)
+}
+
+// Func is a parser for UUID to be used with `env.ParseWithFuncs()`. It is
+// registered with `env.RegisterParser` when the package is imported.
+func Func(v string) (interface{}, error) {
+	return Parse(v)
+}
+
+// Parse parses a UUID in its canonical form, e.g.
+// `f47ac10b-58cc-4372-a567-0e02b2c3d479`, optionally prefixed by `urn:uuid:`
+func Parse(s string) (UUID, error) {
+	var u UUID
+	v := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	if len(v) != 36 || v[8] != '-' || v[13] != '-' || v[18] != '-' || v[23] != '-' {
+		return u, errors.New("Invalid UUID " + s)
+	}
+	if _, err := hex.Decode(u[:], []byte(strings.Replace(v, "-", "", -1))); err != nil {
+		return u, errors.New("Invalid UUID " + s)
+	}
+	return u, nil
+}
+
+// String returns the canonical form of the UUID
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
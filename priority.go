@@ -0,0 +1,160 @@
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// Names of the built-in sources in a priority order, see WithPriority
+const (
+	// PriorityEnv is the Lookuper set by WithLookuper, the process
+	// environment by default
+	PriorityEnv = "env"
+	// PriorityDefault is the `envDefault` tag
+	PriorityDefault = "default"
+)
+
+// WithSource adds a source of values named name, such as "flag" or "file".
+// Sources are consulted after the environment and before the defaults, in
+// the order they were added, unless WithPriority sets another order. Values
+// read from them are reported with name as their source.
+//
+// Failed lookups of a remote source are retried according to retry, if
+// given, or to the policy of WithRetry otherwise:
+//
+//	env.WithSource("vault", vault, env.RetryPolicy{MaxAttempts: 5, Backoff: time.Second})
+func WithSource(name string, l Lookuper, retry ...RetryPolicy) Option {
+	return func(o *options) {
+		if o.sources == nil {
+			o.sources = map[string]Lookuper{}
+		}
+		if _, ok := o.sources[name]; !ok {
+			o.sourceNames = append(o.sourceNames, name)
+		}
+		o.sources[name] = l
+		delete(o.sourceRetry, name)
+		if len(retry) > 0 {
+			if o.sourceRetry == nil {
+				o.sourceRetry = map[string]*RetryPolicy{}
+			}
+			policy := retry[0]
+			o.sourceRetry[name] = &policy
+		}
+	}
+}
+
+// retryOf returns the retry policy of the source name, nil if its lookups
+// aren't retried
+func (o *options) retryOf(name string) *RetryPolicy {
+	if policy, ok := o.sourceRetry[name]; ok {
+		return policy
+	}
+	return o.retry
+}
+
+// WithPriority sets the order in which sources are consulted, as a comma
+// separated list of their names, e.g. "flag,env,file,default": the value of
+// a field comes from the first source holding its variable. Sources left
+// out of the list aren't consulted, except the defaults, which come last.
+// The `envPriority` tag sets the order of a single field.
+func WithPriority(order string) Option {
+	return func(o *options) {
+		o.priority = splitPriority(order)
+	}
+}
+
+func splitPriority(order string) []string {
+	var names []string
+	for _, name := range strings.Split(order, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// order returns the names of the sources of the field described by info, in
+// the order they are consulted
+func (o *options) order(info tagInfo) []string {
+	if info.priority != nil {
+		return info.priority
+	}
+	if o.priority != nil {
+		return o.priority
+	}
+	order := make([]string, 0, len(o.sourceNames)+2)
+	order = append(order, PriorityEnv)
+	order = append(order, o.sourceNames...)
+	return append(order, PriorityDefault)
+}
+
+// lookupSources returns the value of the field described by info from the
+// first source of its priority order holding it, and the source's name, or
+// SourceUnset if none does
+func (o *options) lookupSources(info tagInfo) (string, string, error) {
+	for _, name := range o.order(info) {
+		var (
+			value string
+			ok    bool
+			err   error
+		)
+		switch name {
+		case PriorityDefault:
+			// commands take precedence over defaults
+			if info.defaultValue != "" && !info.required && info.command == nil {
+				return info.defaultValue, SourceDefault, nil
+			}
+			continue
+		case PriorityEnv:
+			value, ok, err = o.lookup(info.key, info.timeout)
+			name = SourceEnvironment
+			o.logSecretAccess(info, name, o.lookuper, ok, err)
+		default:
+			src, found := o.sources[name]
+			if !found {
+				return "", SourceUnset, errors.New("Unknown source " + name + " in the priority order of " + info.key)
+			}
+			value, ok, err = o.lookupIn(src, o.retryOf(name), info.key, info.timeout)
+			o.logSecretAccess(info, name, src, ok, err)
+			if err == nil && ok {
+				value, err = o.decrypt(info.key, value)
+			}
+		}
+		if err != nil {
+			return "", SourceUnset, err
+		}
+		if ok {
+			return value, name, nil
+		}
+	}
+	return "", SourceUnset, nil
+}
+
+// provenance returns where the value of key read from source comes from, or
+// "" for the process environment and other sources telling nothing
+func (o *options) provenance(key, source string) string {
+	var src Lookuper
+	switch source {
+	case SourceEnvironment:
+		src = o.lookuper
+	case SourceDefault, SourcePrompt, SourceUnset:
+		return ""
+	default:
+		src = o.sources[source]
+	}
+	if p, ok := src.(Provenancer); ok {
+		return p.Provenance(key)
+	}
+	if source != SourceEnvironment {
+		return "value from " + source
+	}
+	return ""
+}
+
+// withProvenance adds the provenance of the value of key to err
+func (o *options) withProvenance(err error, key, source string) error {
+	if p := o.provenance(key, source); p != "" {
+		return errors.New(err.Error() + " (" + p + ")")
+	}
+	return err
+}
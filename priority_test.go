@@ -0,0 +1,73 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority(t *testing.T) {
+	type config struct {
+		Host    string `env:"HOST" envDefault:"localhost"`
+		Port    int    `env:"PORT" envDefault:"80"`
+		Debug   bool   `env:"DEBUG"`
+		Timeout string `env:"TIMEOUT" envDefault:"5s" envPriority:"default,env"`
+	}
+	environ := env.Map{"HOST": "env", "PORT": "8080", "TIMEOUT": "1s"}
+	flags := env.Map{"HOST": "flag"}
+	file := env.Map{"PORT": "9090", "DEBUG": "true"}
+
+	cfg := config{}
+	report := env.Report{}
+	err := env.Parse(&cfg, env.WithLookuper(environ), env.WithSource("flag", flags), env.WithSource("file", file), env.WithReport(&report))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Host: "env", Port: 8080, Debug: true, Timeout: "5s"}, cfg)
+	assert.Equal(t, "file", report.Fields[2].Source)
+
+	cfg = config{}
+	err = env.Parse(&cfg, env.WithLookuper(environ), env.WithSource("flag", flags), env.WithSource("file", file), env.WithPriority("flag, file, env, default"))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Host: "flag", Port: 9090, Debug: true, Timeout: "5s"}, cfg)
+
+	// sources left out aren't consulted, but defaults still apply
+	cfg = config{}
+	err = env.Parse(&cfg, env.WithLookuper(environ), env.WithSource("file", file), env.WithPriority("file"))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Host: "localhost", Port: 9090, Debug: true, Timeout: "5s"}, cfg)
+}
+
+func TestPriorityUnknownSource(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+	}
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{}), env.WithPriority("flag,env"))
+	assert.Equal(t, errors.New("Unknown source flag in the priority order of HOST"), err)
+}
+
+type lineMap map[string]string
+
+func (m lineMap) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m lineMap) Provenance(key string) string {
+	return "value from .env file ./.env line 12"
+}
+
+func TestProvenanceInErrors(t *testing.T) {
+	type config struct {
+		Port  int  `env:"PORT"`
+		Debug bool `env:"DEBUG"`
+	}
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{"DEBUG": "maybe"}), env.WithSource("file", lineMap{"PORT": "http"}))
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "http": invalid syntax (value from .env file ./.env line 12). strconv.ParseBool: parsing "maybe": invalid syntax`)
+
+	err = env.Parse(&config{}, env.WithLookuper(env.Map{}), env.WithSource("flag", env.Map{"PORT": "http"}))
+	assert.EqualError(t, err, `strconv.ParseInt: parsing "http": invalid syntax (value from flag)`)
+
+	err = env.Parse(&config{}, env.WithLookuper(lineMap{"DEBUG": "maybe"}))
+	assert.EqualError(t, err, `strconv.ParseBool: parsing "maybe": invalid syntax (value from .env file ./.env line 12)`)
+}
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Prompter asks the user for the value of key, a missing required variable.
+// secret is true for fields tagged with the `secret` option, whose input
+// should be hidden.
+type Prompter func(key string, secret bool) (string, error)
+
+// WithPrompter asks p for the values of missing required variables instead
+// of failing, e.g. for the first run of a CLI tool. If p returns an empty
+// value, the variable is reported as missing.
+func WithPrompter(p Prompter) Option {
+	return func(o *options) {
+		o.prompter = p
+	}
+}
+
+// NewPrompter returns a Prompter writing prompts to out and reading lines
+// from in. When in is a terminal, the input of secrets is hidden with stty.
+// `NewPrompter(os.Stdin, os.Stderr)` prompts on the terminal.
+func NewPrompter(in io.Reader, out io.Writer) Prompter {
+	r := bufio.NewReader(in)
+	return func(key string, secret bool) (string, error) {
+		fmt.Fprintf(out, "%s: ", key)
+		if f, ok := in.(*os.File); ok && secret && stty(f, "-echo") == nil {
+			defer func() {
+				stty(f, "echo")
+				fmt.Fprintln(out)
+			}()
+		}
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+func stty(tty *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
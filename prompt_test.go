@@ -0,0 +1,36 @@
+package env_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrompter(t *testing.T) {
+	type config struct {
+		User     string `env:"USER,required"`
+		Password string `env:"PASSWORD,required,secret"`
+		Home     string `env:"HOME"`
+	}
+	var out bytes.Buffer
+	prompter := env.NewPrompter(strings.NewReader("admin\nhunter2\n"), &out)
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithPrompter(prompter), env.WithLookuper(env.Map{})))
+	assert.Equal(t, config{User: "admin", Password: "hunter2"}, cfg)
+	assert.Equal(t, "USER: PASSWORD: ", out.String())
+}
+
+func TestPrompterErrors(t *testing.T) {
+	type config struct {
+		User string `env:"USER,required"`
+	}
+	cfg := config{}
+	empty := func(key string, secret bool) (string, error) { return "", nil }
+	assert.EqualError(t, env.Parse(&cfg, env.WithPrompter(empty), env.WithLookuper(env.Map{})), "Required environment variable USER is not set")
+	failing := func(key string, secret bool) (string, error) { return "", errors.New("no terminal") }
+	assert.EqualError(t, env.Parse(&cfg, env.WithPrompter(failing), env.WithLookuper(env.Map{})), "no terminal")
+}
@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// number lists the types Range supports
+type number interface {
+	~int | ~int64 | ~uint | ~float32 | ~float64
+}
+
+// Range is an inclusive range of numbers, such as a port range or an ID
+// window, read from `min-max`, e.g. `8000-8999`, or from a single number.
+// The bounds are parsed like a field of type T, e.g. `1s-5m` for durations.
+type Range[T number] struct {
+	Min, Max T
+}
+
+// Contains reports whether v is within the range, bounds included
+func (r Range[T]) Contains(v T) bool {
+	return r.Min <= v && v <= r.Max
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *Range[T]) UnmarshalText(text []byte) error {
+	return r.unmarshalWith(string(text), defaultConversion())
+}
+
+func (r *Range[T]) unmarshalWith(text string, c *conversion) error {
+	s := strings.TrimSpace(text)
+	lo, hi := s, s
+	// the lower bound may be negative
+	unsigned := strings.TrimPrefix(s, "-")
+	if i := strings.Index(unsigned, "-"); i >= 0 {
+		i += len(s) - len(unsigned)
+		lo, hi = s[:i], s[i+1:]
+	}
+	var parsed Range[T]
+	t := reflect.TypeOf(parsed.Min)
+	loValue, err := convert(strings.TrimSpace(lo), t, c)
+	if err != nil {
+		return errors.New("Invalid range " + text + ", expected min-max")
+	}
+	hiValue, err := convert(strings.TrimSpace(hi), t, c)
+	if err != nil {
+		return errors.New("Invalid range " + text + ", expected min-max")
+	}
+	parsed.Min, parsed.Max = loValue.Interface().(T), hiValue.Interface().(T)
+	if parsed.Min > parsed.Max {
+		return errors.New("Invalid range " + text + ", expected min <= max")
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, writing `min-max` as read
+// by UnmarshalText, e.g. `1s-5s` for durations
+func (r Range[T]) MarshalText() ([]byte, error) {
+	return []byte(formatBound(r.Min) + "-" + formatBound(r.Max)), nil
+}
+
+// formatBound writes a bound of a Range, without exponent for floats, whose
+// `-` would be read as the separator
+func formatBound[T number](v T) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,75 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRange(t *testing.T) {
+	type config struct {
+		Ports   env.Range[int]     `env:"PORTS"`
+		Offsets env.Range[int64]   `env:"OFFSETS"`
+		Ratio   env.Range[float64] `env:"RATIO"`
+		Single  env.Range[uint]    `env:"SINGLE"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"PORTS":   "8000-8999",
+		"OFFSETS": "-10--5",
+		"RATIO":   "0.5 - 1.5",
+		"SINGLE":  "42",
+	})))
+	assert.Equal(t, env.Range[int]{Min: 8000, Max: 8999}, cfg.Ports)
+	assert.Equal(t, env.Range[int64]{Min: -10, Max: -5}, cfg.Offsets)
+	assert.Equal(t, env.Range[float64]{Min: 0.5, Max: 1.5}, cfg.Ratio)
+	assert.Equal(t, env.Range[uint]{Min: 42, Max: 42}, cfg.Single)
+	assert.True(t, cfg.Ports.Contains(8999))
+	assert.False(t, cfg.Ports.Contains(9000))
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"PORTS": "9000-8000"}))
+	assert.Equal(t, errors.New("Invalid range 9000-8000, expected min <= max"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"PORTS": "a-b"}))
+	assert.Equal(t, errors.New("Invalid range a-b, expected min-max"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"SINGLE": "-1-5"}))
+	assert.Equal(t, errors.New("Invalid range -1-5, expected min-max"), err)
+}
+
+func TestDurationRange(t *testing.T) {
+	type config struct {
+		Timeout env.Range[time.Duration] `env:"TIMEOUT"`
+		Expiry  env.Range[time.Duration] `env:"EXPIRY,extendedDuration"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"TIMEOUT": "1s-5s", "EXPIRY": "1d-2w"})))
+	assert.Equal(t, env.Range[time.Duration]{Min: time.Second, Max: 5 * time.Second}, cfg.Timeout)
+	assert.Equal(t, env.Range[time.Duration]{Min: 24 * time.Hour, Max: 14 * 24 * time.Hour}, cfg.Expiry)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"TIMEOUT": "1d-2d"}))
+	assert.Equal(t, errors.New("Invalid range 1d-2d, expected min-max"), err)
+
+	var r env.Range[time.Duration]
+	assert.NoError(t, r.UnmarshalText([]byte("-1m--30s")))
+	assert.Equal(t, env.Range[time.Duration]{Min: -time.Minute, Max: -30 * time.Second}, r)
+}
+
+func TestRangeRoundTrip(t *testing.T) {
+	durations := env.Range[time.Duration]{Min: -time.Second, Max: 90 * time.Minute}
+	text, err := durations.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "-1s-1h30m0s", string(text))
+	var parsedDurations env.Range[time.Duration]
+	assert.NoError(t, parsedDurations.UnmarshalText(text))
+	assert.Equal(t, durations, parsedDurations)
+
+	floats := env.Range[float64]{Min: -0.00001, Max: 1e21}
+	text, err = floats.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.00001-1000000000000000000000", string(text))
+	var parsedFloats env.Range[float64]
+	assert.NoError(t, parsedFloats.UnmarshalText(text))
+	assert.Equal(t, floats, parsedFloats)
+}
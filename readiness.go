@@ -0,0 +1,66 @@
+package env
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Readiness reflects whether the last parse or reload of a config succeeded,
+// so health endpoints can report misconfiguration distinctly from crashes.
+// The zero value is ready. A Readiness is safe for concurrent use.
+type Readiness struct {
+	mu     sync.RWMutex
+	err    error
+	failed []string
+}
+
+// Set records the result of a parse: its error, nil if it succeeded, and the
+// report filled by `WithReport`, if any, whose failed fields are kept
+func (r *Readiness) Set(report *Report, err error) {
+	var failed []string
+	if report != nil && err != nil {
+		failed = report.failed()
+	}
+	r.set(failed, err)
+}
+
+func (r *Readiness) set(failed []string, err error) {
+	r.mu.Lock()
+	r.err, r.failed = err, failed
+	r.mu.Unlock()
+}
+
+// Check returns the error of the last parse, nil if it succeeded. Its
+// signature matches the checks of most health libraries.
+func (r *Readiness) Check(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+// FailedFields returns the paths of the fields that failed the last parse
+func (r *Readiness) FailedFields() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failed
+}
+
+// ServeHTTP responds with 200 OK if the last parse succeeded, or with 503
+// Service Unavailable and the failed fields and error otherwise
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	err, failed := r.err, r.failed
+	r.mu.RUnlock()
+	if err == nil {
+		w.Write([]byte("ok\n"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if len(failed) > 0 {
+		w.Write([]byte("failed fields: " + strings.Join(failed, ", ") + "\n"))
+	}
+	w.Write([]byte(err.Error() + "\n"))
+}
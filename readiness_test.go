@@ -0,0 +1,49 @@
+package env_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	environ := env.Map{"PORT": "8080", "LEVEL": "info"}
+	h, err := env.NewHolder(func() interface{} { return &reloadConfig{} }, env.WithLookuper(environ))
+	assert.NoError(t, err)
+	r := h.Readiness()
+	assert.NoError(t, r.Check(context.Background()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	environ["PORT"] = "x"
+	_, err = h.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, err, r.Check(context.Background()))
+	assert.Equal(t, []string{"Port"}, r.FailedFields())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "failed fields: Port\nstrconv.ParseInt: parsing \"x\": invalid syntax\n", w.Body.String())
+
+	environ["PORT"] = "8080"
+	_, err = h.Reload()
+	assert.NoError(t, err)
+	assert.NoError(t, r.Check(context.Background()))
+	assert.Empty(t, r.FailedFields())
+}
+
+func TestReadinessSet(t *testing.T) {
+	var r env.Readiness
+	report := env.Report{}
+	err := env.Parse(&reportConfig{}, env.WithReport(&report), env.WithLookuper(env.Map{"DEBUG": "maybe"}))
+	r.Set(&report, err)
+	assert.Equal(t, err, r.Check(context.Background()))
+	assert.Equal(t, []string{"Debug"}, r.FailedFields())
+}
@@ -0,0 +1,137 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Refreshing is a field whose variable is resolved by Parse and resolved
+// again later, for secrets that rotate, such as database passwords:
+//
+//	type config struct {
+//		Password env.Refreshing[string] `env:"DB_PASSWORD" envRefresh:"15m"`
+//	}
+//
+// With `envRefresh`, Get resolves the variable again once the interval has
+// elapsed since the last resolution. Refresh resolves it on demand, e.g.
+// when authentication fails with the current value. Lookups made after Parse
+// use a background context. A Refreshing must not be copied after Parse.
+type Refreshing[T any] struct {
+	mu       sync.RWMutex
+	resolve  func(reflect.Type) (reflect.Value, error)
+	interval time.Duration
+	resolved time.Time
+	// attempted is the time of the last resolution, successful or not
+	attempted time.Time
+	value     T
+	callbacks []func(T)
+}
+
+// Get returns the current value, resolving it again first if the refresh
+// interval elapsed. If that fails, the current value is kept and the next
+// attempt waits for another interval, so a failing source isn't read on
+// every Get.
+func (r *Refreshing[T]) Get() T {
+	r.mu.RLock()
+	value, stale := r.value, r.interval > 0 && time.Since(r.attempted) >= r.interval
+	r.mu.RUnlock()
+	if stale {
+		if v, err := r.Refresh(); err == nil {
+			value = v
+		}
+	}
+	return value
+}
+
+// Refresh resolves the variable again and returns the new value. On error,
+// the current value is kept and returned.
+func (r *Refreshing[T]) Refresh() (T, error) {
+	r.mu.Lock()
+	if r.resolve == nil {
+		defer r.mu.Unlock()
+		return r.value, nil
+	}
+	value, err := r.fetch()
+	r.attempted = time.Now()
+	if err != nil {
+		defer r.mu.Unlock()
+		return r.value, err
+	}
+	changed := !reflect.DeepEqual(value, r.value)
+	r.value, r.resolved = value, r.attempted
+	callbacks := r.callbacks
+	r.mu.Unlock()
+	if changed {
+		for _, f := range callbacks {
+			f(value)
+		}
+	}
+	return value, nil
+}
+
+// OnChange adds a function called with the new value whenever a refresh
+// changes it, e.g. so a connection pool can re-authenticate
+func (r *Refreshing[T]) OnChange(f func(T)) {
+	r.mu.Lock()
+	r.callbacks = append(r.callbacks, f)
+	r.mu.Unlock()
+}
+
+func (r *Refreshing[T]) fetch() (T, error) {
+	var value T
+	v, err := r.resolve(reflect.TypeOf(&value).Elem())
+	if err != nil {
+		return value, err
+	}
+	if v.IsValid() {
+		value = v.Interface().(T)
+	}
+	return value, nil
+}
+
+// init resolves the variable with first, during Parse, and sets the
+// function resolving it again later
+func (r *Refreshing[T]) init(first, resolve func(reflect.Type) (reflect.Value, error), interval time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolve, r.interval = first, interval
+	value, err := r.fetch()
+	r.resolve = resolve
+	if err != nil {
+		return err
+	}
+	r.value, r.resolved = value, time.Now()
+	r.attempted = r.resolved
+	return nil
+}
+
+// current returns the current value without refreshing it
+func (r *Refreshing[T]) current() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.value
+}
+
+// currentValuer is implemented by Refreshing fields, whose value must be
+// read under their lock
+type currentValuer interface {
+	current() interface{}
+}
+
+var currentValuerType = reflect.TypeOf((*currentValuer)(nil)).Elem()
+
+// refreshingField is implemented by Refreshing fields
+type refreshingField interface {
+	init(first, resolve func(reflect.Type) (reflect.Value, error), interval time.Duration) error
+}
+
+// asRefreshing returns field as a refreshingField, if it is a settable
+// Refreshing
+func asRefreshing(field reflect.Value) (refreshingField, bool) {
+	if !field.CanSet() {
+		return nil, false
+	}
+	refreshing, ok := field.Addr().Interface().(refreshingField)
+	return refreshing, ok
+}
@@ -0,0 +1,107 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// mutableLookuper is a Map safe for concurrent updates
+type mutableLookuper struct {
+	mu sync.Mutex
+	m  env.Map
+}
+
+func (l *mutableLookuper) Lookup(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.m.Lookup(key)
+}
+
+func (l *mutableLookuper) set(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.m[key] = value
+}
+
+func TestRefreshing(t *testing.T) {
+	type config struct {
+		Password env.Refreshing[string] `env:"PASSWORD,required"`
+		Limit    env.Refreshing[int]    `env:"LIMIT" envRefresh:"10ms"`
+	}
+	l := &mutableLookuper{m: env.Map{"PASSWORD": "old", "LIMIT": "1"}}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l)))
+	assert.Equal(t, "old", cfg.Password.Get())
+	assert.Equal(t, 1, cfg.Limit.Get())
+
+	var changes []string
+	cfg.Password.OnChange(func(v string) { changes = append(changes, v) })
+	l.set("PASSWORD", "new")
+	l.set("LIMIT", "2")
+	assert.Equal(t, "old", cfg.Password.Get())
+	password, err := cfg.Password.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, "new", password)
+	assert.Equal(t, "new", cfg.Password.Get())
+	assert.Equal(t, []string{"new"}, changes)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 2, cfg.Limit.Get())
+
+	l.set("LIMIT", "x")
+	_, err = cfg.Limit.Refresh()
+	assert.Error(t, err)
+	assert.Equal(t, 2, cfg.Limit.Get())
+}
+
+func TestRefreshingRequired(t *testing.T) {
+	type config struct {
+		Password env.Refreshing[string] `env:"PASSWORD,required"`
+	}
+	err := env.Parse(&config{}, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Required environment variable PASSWORD is not set"), err)
+}
+
+func TestRefreshingContext(t *testing.T) {
+	type ctxKey struct{}
+	type config struct {
+		Level env.Refreshing[string] `env:"LEVEL"`
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	l := &ctxLookuper{}
+	cfg := config{}
+	assert.NoError(t, env.ParseContext(ctx, &cfg, env.WithLookuper(l)))
+	assert.Equal(t, "value", l.ctx.Value(ctxKey{}))
+
+	_, err := cfg.Level.Refresh()
+	assert.NoError(t, err)
+	assert.Nil(t, l.ctx.Value(ctxKey{}))
+}
+
+func TestRefreshingBacksOffAfterFailure(t *testing.T) {
+	type config struct {
+		Limit env.Refreshing[int] `env:"LIMIT" envRefresh:"50ms"`
+	}
+	l := &countingLookuper{Map: env.Map{"LIMIT": "1"}}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l)))
+	assert.Equal(t, 1, l.calls)
+
+	l.Map["LIMIT"] = "x"
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 1, cfg.Limit.Get())
+	assert.Equal(t, 2, l.calls)
+	assert.Equal(t, 1, cfg.Limit.Get())
+	assert.Equal(t, 2, l.calls)
+
+	l.Map["LIMIT"] = "2"
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 2, cfg.Limit.Get())
+	assert.Equal(t, 3, l.calls)
+}
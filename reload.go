@@ -0,0 +1,139 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Holder holds a config that is reloaded from the environment while other
+// goroutines read it. Readers get the current config with Get and must not
+// modify it; Reload parses a new config and swaps it in atomically.
+type Holder struct {
+	newFn func() interface{}
+	opts  []Option
+
+	// serializes reloads
+	mu        sync.Mutex
+	current   atomic.Value // *holderState
+	onReload  func([]FieldChange, error)
+	readiness Readiness
+}
+
+// holderState is the current config of a Holder and the report of its parse
+type holderState struct {
+	config interface{}
+	report *Report
+}
+
+// NewHolder parses the config returned by newFn and returns a Holder for it.
+// newFn must return a pointer to a new struct, with the pointers to its
+// nested structs allocated, as each reload parses a new config.
+func NewHolder(newFn func() interface{}, opts ...Option) (*Holder, error) {
+	h := &Holder{newFn: newFn, opts: opts}
+	state, err := h.parse(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	h.current.Store(state)
+	return h, nil
+}
+
+// OnReload sets a function called after each reload with its result, e.g.
+// to log the changes or errors of reloads triggered by `ReloadOnSignal`
+func (h *Holder) OnReload(f func(changes []FieldChange, err error)) {
+	h.mu.Lock()
+	h.onReload = f
+	h.mu.Unlock()
+}
+
+// Get returns the current config, a pointer of the type returned by newFn
+func (h *Holder) Get() interface{} {
+	return h.current.Load().(*holderState).config
+}
+
+// Report returns the report of the parse of the current config
+func (h *Holder) Report() *Report {
+	return h.current.Load().(*holderState).report
+}
+
+func (h *Holder) parse(ctx context.Context) (*holderState, error) {
+	state := &holderState{config: h.newFn(), report: &Report{}}
+	opts := append(append([]Option{}, h.opts...), WithReport(state.report))
+	return state, ParseContext(ctx, state.config, opts...)
+}
+
+// Reload is the same as `ReloadContext` with a background context
+func (h *Holder) Reload() ([]FieldChange, error) {
+	return h.ReloadContext(context.Background())
+}
+
+// ReloadContext parses a new config, swaps it in and returns the fields that
+// changed. If parsing fails or a field tagged `envReload:"forbid"` changed,
+// the current config is kept and an error is returned.
+func (h *Holder) ReloadContext(ctx context.Context) ([]FieldChange, error) {
+	h.mu.Lock()
+	changes, failed, err := h.reload(ctx)
+	h.readiness.set(failed, err)
+	onReload := h.onReload
+	h.mu.Unlock()
+	// called unlocked, so that it can use h
+	if onReload != nil {
+		onReload(changes, err)
+	}
+	return changes, err
+}
+
+// reload returns the changes of the new config, or the fields that failed
+// and the error
+func (h *Holder) reload(ctx context.Context) ([]FieldChange, []string, error) {
+	state, err := h.parse(ctx)
+	if err != nil {
+		ForgetDrift(state.config)
+		return nil, state.report.failed(), err
+	}
+	previous := h.Get()
+	changes := Diff(previous, state.config, h.opts...)
+	var failed, errorList []string
+	for _, c := range changes {
+		if c.Forbidden {
+			failed = append(failed, c.Field)
+			errorList = append(errorList, "Field "+c.Field+" ("+c.Key+") can't change without a restart")
+		}
+	}
+	if len(errorList) > 0 {
+		ForgetDrift(state.config)
+		return changes, failed, errors.New(strings.Join(errorList, ". "))
+	}
+	h.current.Store(state)
+	ForgetDrift(previous)
+	return changes, nil, nil
+}
+
+// Readiness reports whether the last reload succeeded
+func (h *Holder) Readiness() *Readiness {
+	return &h.readiness
+}
+
+// ReloadOnSignal reloads h whenever the process receives one of the given
+// signals, conventionally SIGHUP, until ctx is done. It returns immediately;
+// use `Holder.OnReload` to be notified of the reloads.
+func ReloadOnSignal(ctx context.Context, h *Holder, sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				h.ReloadContext(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,78 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type reloadConfig struct {
+	Port  int    `env:"PORT" envReload:"forbid"`
+	Level string `env:"LEVEL"`
+}
+
+func TestHolder(t *testing.T) {
+	environ := env.Map{"PORT": "8080", "LEVEL": "info"}
+	h, err := env.NewHolder(func() interface{} { return &reloadConfig{} }, env.WithLookuper(environ))
+	assert.NoError(t, err)
+	assert.Equal(t, &reloadConfig{Port: 8080, Level: "info"}, h.Get())
+
+	environ["LEVEL"] = "debug"
+	changes, err := h.Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []env.FieldChange{{Field: "Level", Key: "LEVEL", Old: "info", New: "debug"}}, changes)
+	assert.Equal(t, &reloadConfig{Port: 8080, Level: "debug"}, h.Get())
+
+	environ["PORT"] = "9090"
+	environ["LEVEL"] = "warn"
+	changes, err = h.Reload()
+	assert.EqualError(t, err, "Field Port (PORT) can't change without a restart")
+	assert.Len(t, changes, 2)
+	assert.True(t, changes[0].Forbidden)
+	assert.Equal(t, &reloadConfig{Port: 8080, Level: "debug"}, h.Get())
+
+	environ["PORT"] = "x"
+	_, err = h.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, &reloadConfig{Port: 8080, Level: "debug"}, h.Get())
+}
+
+func TestHolderRefreshing(t *testing.T) {
+	type config struct {
+		Region env.Refreshing[string] `env:"REGION" envReload:"forbid"`
+		Level  string                 `env:"LEVEL"`
+	}
+	environ := env.Map{"REGION": "eu", "LEVEL": "info"}
+	h, err := env.NewHolder(func() interface{} { return &config{} }, env.WithLookuper(environ))
+	assert.NoError(t, err)
+
+	environ["LEVEL"] = "debug"
+	changes, err := h.Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []env.FieldChange{{Field: "Level", Key: "LEVEL", Old: "info", New: "debug"}}, changes)
+
+	environ["REGION"] = "us"
+	_, err = h.Reload()
+	assert.EqualError(t, err, "Field Region (REGION) can't change without a restart")
+}
+
+func TestOnReloadUsesHolder(t *testing.T) {
+	h, err := env.NewHolder(func() interface{} { return &reloadConfig{} }, env.WithLookuper(env.Map{"PORT": "8080"}))
+	assert.NoError(t, err)
+	var got interface{}
+	h.OnReload(func(changes []env.FieldChange, err error) {
+		h.OnReload(nil)
+		got = h.Get()
+	})
+	_, err = h.Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, &reloadConfig{Port: 8080}, got)
+}
+
+func TestInvalidReloadTag(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT" envReload:"never"`
+	}
+	assert.EqualError(t, env.Parse(&config{}, env.WithLookuper(env.Map{})), "Invalid envReload never, expected allow or forbid")
+}
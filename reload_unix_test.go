@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package env_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	environ := &mutableLookuper{m: env.Map{"PORT": "8080", "LEVEL": "info"}}
+	h, err := env.NewHolder(func() interface{} { return &reloadConfig{} }, env.WithLookuper(environ))
+	assert.NoError(t, err)
+	reloaded := make(chan error, 1)
+	h.OnReload(func(changes []env.FieldChange, err error) {
+		reloaded <- err
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	env.ReloadOnSignal(ctx, h, syscall.SIGHUP)
+
+	environ.set("LEVEL", "debug")
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Signal(syscall.SIGHUP))
+	assert.NoError(t, <-reloaded)
+	assert.Equal(t, &reloadConfig{Port: 8080, Level: "debug"}, h.Get())
+}
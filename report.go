@@ -0,0 +1,108 @@
+package env
+
+import (
+	"reflect"
+)
+
+// Sources of the values of fields, as reported in a Report
+const (
+	// SourceEnvironment is the source of values read from the Lookuper
+	SourceEnvironment = "environment"
+	// SourceDefault is the source of values set from `envDefault`
+	SourceDefault = "default"
+	// SourcePrompt is the source of values entered by the user, see
+	// WithPrompter
+	SourcePrompt = "prompt"
+	// SourceCommand is the source of values printed by the command of the
+	// `envSource` tag
+	SourceCommand = "command"
+	// SourceUnset is the source of fields left untouched
+	SourceUnset = "unset"
+)
+
+// Report lists the fields set by Parse, see `WithReport` and `DryRun`
+type Report struct {
+	Fields []FieldReport `json:"fields"`
+}
+
+// FieldReport describes how a field was resolved
+type FieldReport struct {
+	// Field is the dotted path of the struct field, e.g. "Database.Host"
+	Field string `json:"field"`
+	// Key is the environment variable the field is loaded from
+	Key string `json:"key,omitempty"`
+	// Source is where the value comes from, one of the Source constants or
+	// the name of a source added with WithSource
+	Source string `json:"source"`
+	// Value is the value of the field after parsing. Fields tagged with the
+	// `secret` option have their value replaced by a redacted marker.
+	Value  interface{} `json:"value,omitempty"`
+	Secret bool        `json:"secret,omitempty"`
+	// Error is the error parsing the field, if any
+	Error string `json:"error,omitempty"`
+	// Warning is the error ignored parsing the field, see `envOnError`
+	Warning string `json:"warning,omitempty"`
+}
+
+// WithReport fills r with a report of the fields resolved by Parse
+func WithReport(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// DryRun resolves and converts the fields of v as Parse would, returning a
+// report of the result and the errors Parse would return, but leaves v
+// untouched. It allows checking a new environment before applying it.
+func DryRun(v interface{}, opts ...Option) (*Report, error) {
+	ptrRef := reflect.ValueOf(v)
+	if ptrRef.Kind() != reflect.Ptr || ptrRef.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+	r := &Report{}
+	err := Parse(clone(ptrRef).Interface(), append(opts, WithReport(r))...)
+	return r, err
+}
+
+// record adds a field to the report, if any
+func (o *options) record(fieldPath string, info tagInfo, source string, field reflect.Value, err error) {
+	if o.report == nil {
+		return
+	}
+	fr := FieldReport{
+		Field:  fieldPath,
+		Key:    info.key,
+		Source: source,
+		Value:  field.Interface(),
+		Secret: info.secret,
+	}
+	if info.secret {
+		fr.Value = redacted
+	}
+	if err != nil {
+		fr.Value = nil
+		fr.Error = err.Error()
+	}
+	o.report.Fields = append(o.report.Fields, fr)
+}
+
+// recordWarning adds a field whose value couldn't be parsed, but whose error
+// was ignored, to the report and the warnings, if any
+func (o *options) recordWarning(fieldPath string, info tagInfo, source string, field reflect.Value, warning error) {
+	o.warn(fieldPath, info.key, warning.Error())
+	o.record(fieldPath, info, source, field, nil)
+	if o.report != nil {
+		o.report.Fields[len(o.report.Fields)-1].Warning = warning.Error()
+	}
+}
+
+// failed returns the fields that failed to parse
+func (r *Report) failed() []string {
+	var fields []string
+	for _, f := range r.Fields {
+		if f.Error != "" {
+			fields = append(fields, f.Field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,39 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type reportConfig struct {
+	Host     string `env:"HOST" envDefault:"localhost"`
+	Port     int    `env:"PORT"`
+	Password string `env:"PASSWORD,secret"`
+	Debug    bool   `env:"DEBUG"`
+}
+
+func TestWithReport(t *testing.T) {
+	cfg := reportConfig{}
+	r := env.Report{}
+	assert.NoError(t, env.Parse(&cfg, env.WithReport(&r), env.WithLookuper(env.Map{
+		"PORT":     "8080",
+		"PASSWORD": "hunter2",
+	})))
+	assert.Equal(t, []env.FieldReport{
+		{Field: "Host", Key: "HOST", Source: env.SourceDefault, Value: "localhost"},
+		{Field: "Port", Key: "PORT", Source: env.SourceEnvironment, Value: 8080},
+		{Field: "Password", Key: "PASSWORD", Source: env.SourceEnvironment, Value: "[REDACTED]", Secret: true},
+		{Field: "Debug", Key: "DEBUG", Source: env.SourceUnset, Value: false},
+	}, r.Fields)
+}
+
+func TestDryRun(t *testing.T) {
+	cfg := reportConfig{Port: 80}
+	r, err := env.DryRun(&cfg, env.WithLookuper(env.Map{"PORT": "8080", "DEBUG": "maybe"}))
+	assert.EqualError(t, err, `strconv.ParseBool: parsing "maybe": invalid syntax`)
+	assert.Equal(t, env.FieldReport{Field: "Port", Key: "PORT", Source: env.SourceEnvironment, Value: 8080}, r.Fields[1])
+	assert.Equal(t, env.FieldReport{Field: "Debug", Key: "DEBUG", Source: env.SourceEnvironment, Error: `strconv.ParseBool: parsing "maybe": invalid syntax`}, r.Fields[3])
+	assert.Equal(t, reportConfig{Port: 80}, cfg)
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"reflect"
+)
+
+// Result lists the problems found by Parse that are not errors, see
+// `WithResult` and `ParseWithResult`
+type Result struct {
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// Warning is a problem that doesn't fail Parse, such as a malformed value
+// ignored because of `envOnError`
+type Warning struct {
+	// Field is the dotted path of the struct field, if any
+	Field string `json:"field,omitempty"`
+	// Key is the environment variable concerned
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// String returns the message of the warning
+func (w Warning) String() string {
+	return w.Message
+}
+
+// WithResult fills r with the warnings found by Parse. With it, the unknown
+// variables found by `WithNamespaceAudit` are warnings instead of errors.
+func WithResult(r *Result) Option {
+	return func(o *options) {
+		o.result = r
+	}
+}
+
+// ParseWithResult is the same as `Parse` except it returns the warnings
+// found separately from the errors, so that applications can log the former
+// and fail only on the latter, see `WithResult`
+func ParseWithResult(v interface{}, opts ...Option) (*Result, error) {
+	if ptrRef := reflect.ValueOf(v); ptrRef.Kind() != reflect.Ptr || ptrRef.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+	r := &Result{}
+	err := Parse(v, append(opts, WithResult(r))...)
+	return r, err
+}
+
+// warn adds a warning to the result, if any
+func (o *options) warn(fieldPath, key, message string) {
+	if o.result == nil {
+		return
+	}
+	o.result.Warnings = append(o.result.Warnings, Warning{Field: fieldPath, Key: key, Message: message})
+}
@@ -0,0 +1,35 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWithResult(t *testing.T) {
+	type config struct {
+		Port    int `env:"MYAPP_PORT,required"`
+		Workers int `env:"MYAPP_WORKERS" envDefault:"4" envOnError:"default"`
+	}
+	cfg := config{}
+	r, err := env.ParseWithResult(&cfg, env.WithNamespaceAudit("MYAPP_"), env.WithLookuper(env.Map{
+		"MYAPP_PORT":    "8080",
+		"MYAPP_WORKERS": "many",
+		"MYAPP_TIMEOTU": "5s",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, config{Port: 8080, Workers: 4}, cfg)
+	assert.Equal(t, []env.Warning{
+		{Field: "Workers", Key: "MYAPP_WORKERS", Message: `Invalid value for MYAPP_WORKERS replaced by the default: strconv.ParseInt: parsing "many": invalid syntax`},
+		{Key: "MYAPP_TIMEOTU", Message: "Unknown environment variable MYAPP_TIMEOTU"},
+	}, r.Warnings)
+
+	r, err = env.ParseWithResult(&cfg, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Required environment variable MYAPP_PORT is not set"), err)
+	assert.Empty(t, r.Warnings)
+
+	_, err = env.ParseWithResult(cfg)
+	assert.Equal(t, env.ErrNotAStructPtr, err)
+}
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how failed lookups from a ContextLookuper are retried.
+// Lookupers that only implement Lookuper cannot fail and are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled after each retry
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries, if set
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction of it (0 to 1), so
+	// many instances starting together don't retry in lockstep
+	Jitter float64
+	// Retryable reports whether an error is transient. All errors are
+	// retried if it is nil.
+	Retryable func(err error) bool
+}
+
+// WithRetry retries failed lookups from the configured Lookuper according to
+// the given policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+func (p *RetryPolicy) lookup(ctx context.Context, l ContextLookuper, key string) (string, bool, error) {
+	delay := p.Backoff
+	for attempt := 1; ; attempt++ {
+		value, ok, err := l.LookupContext(ctx, key)
+		if err == nil {
+			return value, ok, nil
+		}
+		if attempt >= p.MaxAttempts || (p.Retryable != nil && !p.Retryable(err)) {
+			if attempt == 1 {
+				return "", false, err
+			}
+			return "", false, retryError(key, attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return "", false, retryError(key, attempt, ctx.Err())
+		case <-time.After(p.jittered(delay)):
+		}
+		delay *= 2
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+	}
+}
+
+// retryError reports a lookup of key that failed after the given number of
+// attempts
+func retryError(key string, attempts int, err error) error {
+	if attempts == 1 {
+		return fmt.Errorf("Lookup of %s failed after 1 attempt: %v", key, err)
+	}
+	return fmt.Errorf("Lookup of %s failed after %d attempts: %v", key, attempts, err)
+}
+
+func (p *RetryPolicy) jittered(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*p.Jitter*float64(d))
+}
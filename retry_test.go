@@ -0,0 +1,110 @@
+package env_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyLookuper struct {
+	failures int
+	calls    int
+}
+
+func (l *flakyLookuper) Lookup(key string) (string, bool) {
+	v, ok, _ := l.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (l *flakyLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	l.calls++
+	if l.calls <= l.failures {
+		return "", false, errors.New("connection reset")
+	}
+	return "value", true, nil
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	type config struct {
+		Var string `env:"VAR"`
+	}
+	l := &flakyLookuper{failures: 2}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(l), env.WithRetry(env.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		Jitter:      0.5,
+	})))
+	assert.Equal(t, "value", cfg.Var)
+	assert.Equal(t, 3, l.calls)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	type config struct {
+		Var string `env:"VAR"`
+	}
+	l := &flakyLookuper{failures: 5}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(l), env.WithRetry(env.RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+	}))
+	assert.EqualError(t, err, "Lookup of VAR failed after 2 attempts: connection reset")
+	assert.Equal(t, 2, l.calls)
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	type config struct {
+		Var string `env:"VAR"`
+	}
+	l := &flakyLookuper{failures: 5}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(l), env.WithRetry(env.RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return false },
+	}))
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 1, l.calls)
+}
+
+func TestRetryContextDone(t *testing.T) {
+	type config struct {
+		Var string `env:"VAR"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	l := &flakyLookuper{failures: 5}
+	cfg := config{}
+	err := env.ParseContext(ctx, &cfg, env.WithLookuper(l), env.WithRetry(env.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+	}))
+	assert.EqualError(t, err, "Lookup of VAR failed after 1 attempt: context deadline exceeded")
+}
+
+func TestRetryPerSource(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN" envPriority:"vault"`
+		Flag  string `env:"FLAG" envPriority:"flags"`
+		Other string `env:"OTHER" envPriority:"store"`
+	}
+	vault := &flakyLookuper{failures: 3}
+	flags := &flakyLookuper{failures: 1}
+	store := &flakyLookuper{failures: 1}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{}),
+		env.WithRetry(env.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}),
+		env.WithSource("vault", vault, env.RetryPolicy{MaxAttempts: 4, Backoff: time.Millisecond}),
+		env.WithSource("flags", flags, env.RetryPolicy{MaxAttempts: 1}),
+		env.WithSource("store", store),
+	)
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 4, vault.calls)
+	assert.Equal(t, 1, flags.calls)
+	assert.Equal(t, 2, store.calls)
+	assert.Equal(t, config{Token: "value", Other: "value"}, cfg)
+}
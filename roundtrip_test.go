@@ -0,0 +1,144 @@
+package env_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"math/rand"
+	"net/mail"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/caarlos0/env/envtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripNested struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT" envDefault:"80"`
+}
+
+type roundTripConfig struct {
+	Name      string                       `env:"NAME,required"`
+	Enabled   bool                         `env:"ENABLED" envDefault:"true"`
+	Count     int                          `env:"COUNT"`
+	Big       int64                        `env:"BIG"`
+	Size      uint                         `env:"SIZE" envBase:"16"`
+	Ratio     float64                      `env:"RATIO"`
+	Small     float32                      `env:"SMALL"`
+	Level     int                          `env:"LEVEL" envClamp:"1:5"`
+	Mode      string                       `env:"MODE" envOneOf:"dev,prod"`
+	Timeout   time.Duration                `env:"TIMEOUT" envMinDuration:"1s" envMaxDuration:"1h"`
+	Perm      os.FileMode                  `env:"PERM"`
+	Optional  *string                      `env:"OPTIONAL"`
+	Tags      []string                     `env:"TAGS"`
+	Ports     []int                        `env:"PORTS" envSeparator:":"`
+	Labels    map[string]string            `env:"LABELS"`
+	Set       map[string]struct{}          `env:"SET"`
+	Headers   map[string]map[string]string `env:"HEADERS"`
+	Admin     mail.Address                 `env:"ADMIN"`
+	Secret    env.SecretString             `env:"SECRET"`
+	Level2    roundTripLevel               `env:"LEVEL2"`
+	Database  roundTripNested              `envPrefix:"DB_"`
+	Cache     *roundTripNested             `envPrefix:"CACHE_"`
+	unexposed string
+}
+
+// roundTripBuiltins has a field of each type with a built-in parser
+type roundTripBuiltins struct {
+	Rate      env.Percent                    `env:"RATE"`
+	Locale    env.LanguageTag                `env:"LOCALE"`
+	Features  env.Features                   `env:"FEATURES"`
+	Price     env.Money                      `env:"PRICE"`
+	Fee       env.Money                      `env:"FEE" envCurrency:"EUR"`
+	Cents     env.Money                      `env:"CENTS,minorUnits"`
+	Ports     env.Range[int]                 `env:"PORTS"`
+	Window    env.Range[time.Duration]       `env:"WINDOW"`
+	Load      env.Range[float64]             `env:"LOAD"`
+	Backends  []env.Weighted[string]         `env:"BACKENDS"`
+	Timeouts  []env.Weighted[time.Duration]  `env:"TIMEOUTS" envSeparator:";"`
+	Total     big.Int                        `env:"TOTAL"`
+	Precise   big.Float                      `env:"PRECISE"`
+	Fraction  *big.Rat                       `env:"FRACTION"`
+	Addresses []*mail.Address                `env:"ADDRESSES"`
+	Raw       json.RawMessage                `env:"RAW"`
+	Limits    map[string]env.Range[uint]     `env:"LIMITS"`
+	Weights   map[string]env.Weighted[int64] `env:"WEIGHTS" envSeparator:";" envKeyValSeparator:"="`
+}
+
+// roundTripLevel generates its own values, and is written as a number
+type roundTripLevel int
+
+func (l roundTripLevel) Generate(r *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(roundTripLevel(r.Intn(10)))
+}
+
+// lossyName drops the case of its value when parsed
+type lossyName string
+
+func (n *lossyName) UnmarshalText(text []byte) error {
+	*n = lossyName(strings.ToLower(string(text)))
+	return nil
+}
+
+func (n lossyName) MarshalText() ([]byte, error) {
+	return []byte(n), nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	err := envtest.Check(&roundTripConfig{}, &quick.Config{Rand: rand.New(rand.NewSource(1)), MaxCount: 500})
+	assert.NoError(t, err)
+}
+
+func TestRoundTripBuiltins(t *testing.T) {
+	err := envtest.Check(&roundTripBuiltins{}, &quick.Config{Rand: rand.New(rand.NewSource(1)), MaxCount: 500})
+	assert.NoError(t, err)
+}
+
+func TestRoundTripQuick(t *testing.T) {
+	f := func(cfg *roundTripConfig) bool {
+		return envtest.RoundTrip(cfg) == nil
+	}
+	c := &quick.Config{Rand: rand.New(rand.NewSource(1)), Values: envtest.Values(&roundTripConfig{})}
+	assert.NoError(t, quick.Check(f, c))
+}
+
+func TestRoundTripAsymmetry(t *testing.T) {
+	type config struct {
+		Name lossyName `env:"NAME"`
+	}
+	err := envtest.RoundTrip(&config{Name: "Foo"})
+	assert.EqualError(t, err, `Field Name didn't survive a round trip: parsed foo, expected Foo`)
+}
+
+func TestRoundTripCheckAsymmetry(t *testing.T) {
+	type config struct {
+		Name lossyName `env:"NAME"`
+	}
+	err := envtest.Check(&config{}, &quick.Config{Rand: rand.New(rand.NewSource(1))})
+	assert.EqualError(t, err, "#1: Field Name didn't survive a round trip: parsed pl, expected pL")
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	type config struct {
+		Link url.URL `env:"LINK"`
+	}
+	err := envtest.Check(&config{}, nil)
+	assert.EqualError(t, err, "Field Link: Type url.URL can't be generated, as it doesn't implement quick.Generator")
+
+	err = envtest.Check(&struct {
+		Retries []int8 `env:"RETRIES"`
+	}{}, nil)
+	assert.EqualError(t, err, "Field Retries: Type int8 can't be generated, as Parse doesn't support its kind")
+
+	_, err = envtest.EnvironFor(struct {
+		Name string `env:"NAME" envDefault:"foo"`
+	}{})
+	assert.Equal(t, errors.New("Field Name is empty, but NAME is required or has a default"), err)
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// syncTypes are the sync primitives, which hold the runtime state of a
+// struct rather than its configuration
+var syncTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.WaitGroup{}): true,
+	reflect.TypeOf(sync.Once{}):      true,
+	reflect.TypeOf(sync.Cond{}):      true,
+	reflect.TypeOf(sync.Map{}):       true,
+	reflect.TypeOf(sync.Pool{}):      true,
+	reflect.TypeOf(atomic.Value{}):   true,
+}
+
+// isRuntimeType reports whether t, or the type it points to, is a channel,
+// a func, an unsafe pointer or a sync primitive, which can't be read from a
+// variable unless a parser is registered for it
+func isRuntimeType(t reflect.Type, convs converters) bool {
+	for ; t.Kind() == reflect.Ptr; t = t.Elem() {
+		if _, ok := convs[t]; ok {
+			return false
+		}
+	}
+	if _, ok := convs[t]; ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return syncTypes[t]
+}
+
+// runtimeFieldError reports a field of a runtime type bound to a variable
+func runtimeFieldError(fieldPath, key string, t reflect.Type) error {
+	return errors.New("Field " + fieldPath + " (" + key + ") is a " + t.String() + ", which can't be read from a variable")
+}
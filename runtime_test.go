@@ -0,0 +1,58 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeFieldsSkipped(t *testing.T) {
+	type runtime struct {
+		Mu      sync.Mutex
+		Lock    *sync.RWMutex
+		Done    chan struct{}
+		OnClose func() error
+	}
+	type config struct {
+		Host    string `env:"HOST"`
+		Runtime runtime
+		Wg      *sync.WaitGroup
+	}
+	cfg := config{Wg: &sync.WaitGroup{}, Runtime: runtime{Lock: &sync.RWMutex{}}}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{"HOST": "localhost"})))
+	assert.Equal(t, "localhost", cfg.Host)
+}
+
+func TestRuntimeFieldsRejected(t *testing.T) {
+	type runtime struct {
+		Done    chan struct{} `env:"DONE"`
+		OnClose func() error  `env:"ON_CLOSE"`
+		Mu      *sync.Mutex   `env:"MU"`
+	}
+	type config struct {
+		Runtime runtime `envPrefix:"APP_"`
+	}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Field Runtime.Done (APP_DONE) is a chan struct {}, which can't be read from a variable. "+
+		"Field Runtime.OnClose (APP_ON_CLOSE) is a func() error, which can't be read from a variable. "+
+		"Field Runtime.Mu (APP_MU) is a *sync.Mutex, which can't be read from a variable"), err)
+}
+
+func TestRuntimeFieldWithParser(t *testing.T) {
+	type handler func() string
+	type config struct {
+		Handler handler `env:"HANDLER"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.ParseWithFuncs(&cfg, env.CustomParsers{
+		reflect.TypeOf(handler(nil)): func(v string) (interface{}, error) {
+			return handler(func() string { return v }), nil
+		},
+	}, env.WithLookuper(env.Map{"HANDLER": "hello"})))
+	assert.Equal(t, "hello", cfg.Handler())
+}
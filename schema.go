@@ -0,0 +1,126 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// jsonSchemaDraft is the JSON Schema version of Schema
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema of the environment a struct expects: an object
+// whose properties are the environment variables, all strings. Go-specific
+// details, such as the field and type, are kept in `x-go-` keywords.
+type Schema struct {
+	Schema     string                     `json:"$schema"`
+	Type       string                     `json:"type"`
+	Properties map[string]*SchemaProperty `json:"properties"`
+	Required   []string                   `json:"required,omitempty"`
+}
+
+// SchemaProperty is the JSON Schema of an environment variable
+type SchemaProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	WriteOnly   bool     `json:"writeOnly,omitempty"`
+	GoField     string   `json:"x-go-field,omitempty"`
+	GoType      string   `json:"x-go-type,omitempty"`
+	Separator   string   `json:"x-separator,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the environment variables read by
+// Parse for the given struct (or pointer to struct), e.g. to validate the
+// environments provided by Helm values or CI pipelines. Marshal it with
+// encoding/json.
+func JSONSchema(v interface{}) (*Schema, error) {
+	vars, err := Describe(v)
+	if err != nil {
+		return nil, err
+	}
+	return SchemaOf(vars), nil
+}
+
+// SchemaOf returns the JSON Schema of the given vars. Secrets are marked
+// `writeOnly`. When a list item is restricted with `envOneOf`, its enum
+// lists the allowed items rather than the allowed values.
+func SchemaOf(vars []Var) *Schema {
+	s := &Schema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: map[string]*SchemaProperty{},
+	}
+	for _, v := range vars {
+		s.Properties[v.Key] = &SchemaProperty{
+			Type:        "string",
+			Description: v.Description,
+			Default:     v.Default,
+			Enum:        v.OneOf,
+			WriteOnly:   v.Secret,
+			GoField:     v.Field,
+			GoType:      v.Type,
+			Separator:   v.Separator,
+		}
+		if v.Required && !hasOption(s.Required, v.Key) {
+			s.Required = append(s.Required, v.Key)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// Vars returns the vars described by the schema, sorted by key, so an
+// environment can be validated against it with Check
+func (s *Schema) Vars() []Var {
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vars := make([]Var, 0, len(keys))
+	for _, k := range keys {
+		p := s.Properties[k]
+		vars = append(vars, Var{
+			Field:       p.GoField,
+			Key:         k,
+			Type:        p.GoType,
+			Default:     p.Default,
+			Required:    hasOption(s.Required, k),
+			Secret:      p.WriteOnly,
+			Separator:   p.Separator,
+			OneOf:       p.Enum,
+			Description: p.Description,
+		})
+	}
+	return vars
+}
+
+// ReadSpec reads a spec of the expected variables: either a JSON list of
+// Vars, as returned by Describe, or a JSON Schema, as returned by JSONSchema
+func ReadSpec(r io.Reader) ([]Var, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.HasPrefix(b, []byte("[")):
+		var vars []Var
+		if err := json.Unmarshal(b, &vars); err != nil {
+			return nil, err
+		}
+		return vars, nil
+	case bytes.HasPrefix(b, []byte("{")):
+		var s Schema
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, err
+		}
+		return s.Vars(), nil
+	}
+	return nil, errors.New("Expected a list of variables or a JSON Schema")
+}
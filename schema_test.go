@@ -0,0 +1,61 @@
+package env_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type config struct {
+		Port     int      `env:"PORT" envDefault:"3000"`
+		Level    string   `env:"LEVEL" envOneOf:"debug,info,warn"`
+		Hosts    []string `env:"HOSTS" envSeparator:":"`
+		Password string   `env:"PASSWORD,required,secret"`
+	}
+	s, err := env.JSONSchema(config{})
+	assert.NoError(t, err)
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"PORT": {"type": "string", "default": "3000", "x-go-field": "Port", "x-go-type": "int"},
+			"LEVEL": {"type": "string", "enum": ["debug", "info", "warn"], "x-go-field": "Level", "x-go-type": "string"},
+			"HOSTS": {"type": "string", "x-go-field": "Hosts", "x-go-type": "[]string", "x-separator": ":"},
+			"PASSWORD": {"type": "string", "writeOnly": true, "x-go-field": "Password", "x-go-type": "string"}
+		},
+		"required": ["PASSWORD"]
+	}`, string(b))
+}
+
+func TestReadSpec(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"PORT": {"type": "string", "x-go-type": "int"},
+			"LEVEL": {"type": "string", "enum": ["debug", "info"]},
+			"SECRET": {"type": "string", "writeOnly": true}
+		},
+		"required": ["SECRET"]
+	}`
+	vars, err := env.ReadSpec(strings.NewReader(schema))
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Var{
+		{Key: "LEVEL", OneOf: []string{"debug", "info"}},
+		{Key: "PORT", Type: "int"},
+		{Key: "SECRET", Required: true, Secret: true},
+	}, vars)
+	assert.Len(t, env.Check(vars, env.Map{"PORT": "x", "LEVEL": "trace"}), 3)
+
+	vars, err = env.ReadSpec(strings.NewReader(`[{"key": "PORT", "type": "int"}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []env.Var{{Key: "PORT", Type: "int"}}, vars)
+
+	_, err = env.ReadSpec(strings.NewReader(`"PORT"`))
+	assert.EqualError(t, err, "Expected a list of variables or a JSON Schema")
+}
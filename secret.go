@@ -0,0 +1,74 @@
+package env
+
+import (
+	"crypto/subtle"
+	"reflect"
+)
+
+// SecretString holds a secret value, such as a password or an API key. It is
+// redacted when printed or encoded to JSON, compares in constant time and
+// can be zeroed once used. Fields of this type are always secret.
+type SecretString struct {
+	b []byte
+}
+
+var secretType = reflect.TypeOf(SecretString{})
+
+// NewSecretString returns a SecretString holding value
+func NewSecretString(value string) SecretString {
+	return SecretString{b: []byte(value)}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *SecretString) UnmarshalText(text []byte) error {
+	s.b = append([]byte(nil), text...)
+	return nil
+}
+
+// Value returns the secret value
+func (s SecretString) Value() string {
+	return string(s.b)
+}
+
+// Bytes returns the secret value without copying it, so it is zeroed by
+// Zero
+func (s SecretString) Bytes() []byte {
+	return s.b
+}
+
+// Empty reports whether the secret is empty
+func (s SecretString) Empty() bool {
+	return len(s.b) == 0
+}
+
+// Equal reports whether the secret is value, in constant time, so the
+// comparison doesn't leak how much of value is right, e.g. to check tokens
+func (s SecretString) Equal(value string) bool {
+	return subtle.ConstantTimeCompare(s.b, []byte(value)) == 1
+}
+
+// Zero overwrites the secret value in memory and empties the secret. This
+// is best effort: copies returned by Value, or made by the runtime, are
+// left untouched.
+func (s *SecretString) Zero() {
+	for i := range s.b {
+		s.b[i] = 0
+	}
+	s.b = nil
+}
+
+// String returns a redacted marker, so secrets don't leak into logs
+func (s SecretString) String() string {
+	return redacted
+}
+
+// GoString returns a redacted marker, for the %#v format
+func (s SecretString) GoString() string {
+	return redacted
+}
+
+// MarshalText implements encoding.TextMarshaler, returning a redacted
+// marker
+func (s SecretString) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
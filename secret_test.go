@@ -0,0 +1,49 @@
+package env_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret(t *testing.T) {
+	type config struct {
+		Password env.SecretString  `env:"PASSWORD"`
+		Token    *env.SecretString `env:"TOKEN"`
+	}
+	report := env.Report{}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"PASSWORD": "s3cr3t", "TOKEN": "t0k3n"}), env.WithReport(&report))
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", cfg.Password.Value())
+	assert.Equal(t, "t0k3n", cfg.Token.Value())
+	assert.True(t, report.Fields[0].Secret)
+	assert.Equal(t, "[REDACTED]", report.Fields[0].Value)
+
+	assert.Equal(t, "[REDACTED] [REDACTED]", fmt.Sprintf("%v %s", cfg.Password, cfg.Token))
+	assert.NotContains(t, fmt.Sprintf("%+v %#v", cfg, cfg), "s3cr3t")
+	b, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Password":"[REDACTED]","Token":"[REDACTED]"}`, string(b))
+
+	assert.True(t, cfg.Password.Equal("s3cr3t"))
+	assert.False(t, cfg.Password.Equal("s3cr3"))
+
+	b = cfg.Password.Bytes()
+	cfg.Password.Zero()
+	assert.True(t, cfg.Password.Empty())
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0}, b)
+}
+
+func TestSecretFingerprint(t *testing.T) {
+	type config struct {
+		Password env.SecretString `env:"PASSWORD"`
+	}
+	a, b := config{Password: env.NewSecretString("a")}, config{Password: env.NewSecretString("b")}
+	assert.Equal(t, env.Fingerprint(a), env.Fingerprint(b))
+	key := env.WithFingerprintKey([]byte("key"))
+	assert.NotEqual(t, env.Fingerprint(a, key), env.Fingerprint(b, key))
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	sectionsMu sync.Mutex
+	// sections holds the configs registered with RegisterSection, by name
+	sections = map[string]interface{}{}
+)
+
+// RegisterSection registers cfg, a pointer to the config struct of a
+// library, under name, so the application parses it with ParseRegistered
+// along with the configs of the other libraries. Libraries typically call
+// it in an init function:
+//
+//	var kafkaCfg kafkaConfig
+//
+//	func init() {
+//		env.RegisterSection("kafka", &kafkaCfg)
+//	}
+//
+// It panics if cfg isn't a pointer to a struct or if name is already
+// registered.
+func RegisterSection(name string, cfg interface{}) {
+	if t := reflect.TypeOf(cfg); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("env: RegisterSection expects a pointer to a struct")
+	}
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	if _, ok := sections[name]; ok {
+		panic("env: RegisterSection called twice for section " + name)
+	}
+	sections[name] = cfg
+}
+
+// ParseRegistered parses the configs registered with RegisterSection, in the
+// order of their names, with the same options. As with ParseMulti, the
+// process environment is read once and the errors of all the sections are
+// returned together, each starting with the name of its section.
+func ParseRegistered(opts ...Option) error {
+	sectionsMu.Lock()
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	cfgs := make(map[string]interface{}, len(sections))
+	for name, cfg := range sections {
+		cfgs[name] = cfg
+	}
+	sectionsMu.Unlock()
+	sort.Strings(names)
+
+	opts = append([]Option{withSnapshot(Snapshot())}, opts...)
+	var errs []error
+	for _, name := range names {
+		if err := Parse(cfgs[name], opts...); err != nil {
+			errs = append(errs, errors.New("Section "+name+": "+err.Error()))
+		}
+	}
+	return joinErrors(errs...)
+}
@@ -0,0 +1,73 @@
+package env_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type kafkaConfig struct {
+	Brokers []string `env:"KAFKA_BROKERS" envDefault:"localhost:9092"`
+}
+
+type metricsConfig struct {
+	Addr  string           `env:"METRICS_ADDR,required"`
+	Token env.Lazy[string] `env:"METRICS_TOKEN"`
+	Later string           `env:"SET_BY_PARSER"`
+}
+
+// auditConfig is parsed first, its value changing the environment
+type auditConfig struct {
+	Value setenvValue `env:"AUDIT_VALUE"`
+}
+
+var (
+	auditSection   auditConfig
+	kafkaSection   kafkaConfig
+	metricsSection metricsConfig
+)
+
+func init() {
+	env.RegisterSection("audit", &auditSection)
+	env.RegisterSection("kafka", &kafkaSection)
+	env.RegisterSection("metrics", &metricsSection)
+}
+
+func TestParseRegistered(t *testing.T) {
+	err := env.ParseRegistered(env.WithLookuper(env.Map{"METRICS_ADDR": ":9100"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost:9092"}, kafkaSection.Brokers)
+	assert.Equal(t, ":9100", metricsSection.Addr)
+
+	err = env.ParseRegistered(env.WithLookuper(env.Map{}))
+	assert.EqualError(t, err, "Section metrics: Required environment variable METRICS_ADDR is not set")
+}
+
+func TestParseRegisteredLazy(t *testing.T) {
+	t.Setenv("METRICS_ADDR", ":9100")
+	t.Setenv("METRICS_TOKEN", "old")
+	assert.NoError(t, env.ParseRegistered())
+	os.Setenv("METRICS_TOKEN", "newer")
+	token, err := metricsSection.Token.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", token)
+}
+
+func TestParseRegisteredReadsOnce(t *testing.T) {
+	t.Setenv("METRICS_ADDR", ":9100")
+	t.Setenv("AUDIT_VALUE", "x")
+	t.Setenv("SET_BY_PARSER", "original")
+	assert.NoError(t, env.ParseRegistered())
+	assert.Equal(t, "original", metricsSection.Later)
+}
+
+func TestRegisterSectionPanics(t *testing.T) {
+	assert.PanicsWithValue(t, "env: RegisterSection called twice for section kafka", func() {
+		env.RegisterSection("kafka", &kafkaConfig{})
+	})
+	assert.PanicsWithValue(t, "env: RegisterSection expects a pointer to a struct", func() {
+		env.RegisterSection("other", kafkaConfig{})
+	})
+}
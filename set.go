@@ -0,0 +1,44 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Set is a set of values, read from a list such as `ALLOWED=a,b,c`, for
+// allowlists and other fields mostly used for membership tests. Any map of
+// empty structs, such as `map[string]struct{}`, is read the same way.
+// Duplicate items are rejected.
+type Set[T comparable] map[T]struct{}
+
+// Has reports whether v is in the set
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// isSet reports whether t is a map of empty structs
+func isSet(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
+
+// handleSet converts value, a list, into a new set of type t
+func handleSet(value string, t reflect.Type, c *conversion) (reflect.Value, error) {
+	items, err := c.splitItems(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	set := reflect.MakeMap(t)
+	member := reflect.New(t.Elem()).Elem()
+	for _, item := range items {
+		k, err := convert(item, t.Key(), c)
+		if err != nil {
+			return set, err
+		}
+		if set.MapIndex(k).IsValid() {
+			return set, errors.New("Duplicate item " + item + " in " + c.key)
+		}
+		set.SetMapIndex(k, member)
+	}
+	return set, nil
+}
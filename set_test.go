@@ -0,0 +1,32 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	type config struct {
+		Allowed env.Set[string]     `env:"ALLOWED"`
+		Ports   env.Set[int]        `env:"PORTS" envSeparator:":"`
+		Admins  map[string]struct{} `env:"ADMINS,trimItems"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"ALLOWED": "a,b,c",
+		"PORTS":   "80:443",
+		"ADMINS":  "alice, bob",
+	})))
+	assert.True(t, cfg.Allowed.Has("b"))
+	assert.False(t, cfg.Allowed.Has("d"))
+	assert.Equal(t, env.Set[int]{80: {}, 443: {}}, cfg.Ports)
+	assert.Equal(t, map[string]struct{}{"alice": {}, "bob": {}}, cfg.Admins)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"ALLOWED": "a,b,a"}))
+	assert.Equal(t, errors.New("Duplicate item a in ALLOWED"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"PORTS": "80:http"}))
+	assert.Error(t, err)
+}
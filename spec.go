@@ -0,0 +1,202 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec configures fields programmatically instead of with struct tags, for
+// codebases that prohibit tags or build specs at runtime:
+//
+//	spec := env.NewSpec().
+//		Field("Port", "PORT", env.Default("3000")).
+//		Field("Database.Password", "DB_PASSWORD", env.Required(), env.Secret())
+//	err := env.Parse(&cfg, env.WithSpec(spec))
+//
+// Fields are bound by path; a field in the spec ignores its struct tags.
+type Spec struct {
+	fields map[string]specField
+}
+
+type specField struct {
+	info tagInfo
+	err  error
+}
+
+// FieldOption configures a field of a Spec, like the options and tags of
+// the `env` tag
+type FieldOption func(*specField)
+
+// NewSpec returns an empty Spec
+func NewSpec() *Spec {
+	return &Spec{fields: map[string]specField{}}
+}
+
+// Field binds the field at path, e.g. `Database.Host`, to the variable key
+func (s *Spec) Field(path, key string, opts ...FieldOption) *Spec {
+	f := specField{info: tagInfo{
+		key:                  key,
+		separator:            ",",
+		keyValSeparator:      ":",
+		innerSeparator:       ";",
+		outerKeyValSeparator: "=",
+		base:                 -1,
+	}}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	s.fields[path] = f
+	return s
+}
+
+// Default sets the value used when the variable is not set, like
+// `envDefault`
+func Default(value string) FieldOption {
+	return func(f *specField) {
+		f.info.defaultValue = value
+	}
+}
+
+// Required makes the variable required, like the `required` option
+func Required() FieldOption {
+	return func(f *specField) {
+		f.info.required = true
+	}
+}
+
+// Secret marks the variable as secret, like the `secret` option
+func Secret() FieldOption {
+	return func(f *specField) {
+		f.info.secret = true
+	}
+}
+
+// Separator sets the separator of slice and map items, like `envSeparator`
+func Separator(sep string) FieldOption {
+	return func(f *specField) {
+		f.info.separator = sep
+	}
+}
+
+// KeyValSeparator sets the separator of map keys and values, like
+// `envKeyValSeparator`
+func KeyValSeparator(sep string) FieldOption {
+	return func(f *specField) {
+		f.info.keyValSeparator = sep
+	}
+}
+
+// InnerSeparator sets the separator of the items of nested maps, like
+// `envInnerSeparator`
+func InnerSeparator(sep string) FieldOption {
+	return func(f *specField) {
+		f.info.innerSeparator = sep
+	}
+}
+
+// OuterKeyValSeparator sets the separator of the keys and nested maps of
+// maps of maps, like `envOuterKeyValSeparator`
+func OuterKeyValSeparator(sep string) FieldOption {
+	return func(f *specField) {
+		f.info.outerKeyValSeparator = sep
+	}
+}
+
+// Trim strips whitespace and quotes from the value, like the `trim` option
+func Trim() FieldOption {
+	return func(f *specField) {
+		f.info.trim = true
+	}
+}
+
+// RelaxedBool accepts yes/no, on/off and enabled/disabled, like the
+// `relaxedBool` option
+func RelaxedBool() FieldOption {
+	return func(f *specField) {
+		f.info.relaxedBool = true
+	}
+}
+
+// ExtendedDuration accepts days and weeks, like the `extendedDuration` option
+func ExtendedDuration() FieldOption {
+	return func(f *specField) {
+		f.info.extendedDuration = true
+	}
+}
+
+// Base sets the base of integers, like `envBase`
+func Base(base int) FieldOption {
+	return func(f *specField) {
+		if base < 0 || base == 1 || base > 36 {
+			f.err = errors.New("Invalid envBase " + strconv.Itoa(base) + ", expected 0 or 2 to 36")
+			return
+		}
+		f.info.base = base
+	}
+}
+
+// OneOf restricts the allowed values, like `envOneOf`
+func OneOf(values ...string) FieldOption {
+	return func(f *specField) {
+		f.info.oneOf = values
+	}
+}
+
+// Timeout bounds the remote lookup of the variable, like `envTimeout`
+func Timeout(d time.Duration) FieldOption {
+	return func(f *specField) {
+		if d <= 0 {
+			f.err = errors.New("Invalid envTimeout " + d.String() + ", expected a positive duration")
+			return
+		}
+		f.info.timeout = d
+	}
+}
+
+// WithSpec configures the fields in s with s instead of their tags
+func WithSpec(s *Spec) Option {
+	return func(o *options) {
+		o.spec = s
+		o.specUsed = map[string]bool{}
+	}
+}
+
+// fieldInfo returns the settings of field, at fieldPath, from the spec or
+// from its tags
+func (o *options) fieldInfo(field reflect.StructField, fieldPath string) (tagInfo, error) {
+	if o.spec != nil {
+		if f, ok := o.spec.fields[fieldPath]; ok {
+			o.specUsed[fieldPath] = true
+			info := f.info
+			info.secret = info.secret || isSecretType(field.Type)
+			return info, f.err
+		}
+	}
+	info, err := parseTag(field)
+	if err == nil && info.key == "" && len(o.fallbackTags) > 0 {
+		info.key = o.fallbackKey(field)
+	}
+	return info, err
+}
+
+// unusedSpec reports the fields of the spec that match no struct field
+func (o *options) unusedSpec() error {
+	if o.spec == nil {
+		return nil
+	}
+	var errorList []string
+	for p := range o.spec.fields {
+		if !o.specUsed[p] && o.selected(p) {
+			errorList = append(errorList, "Field "+p+" not found")
+		}
+	}
+	if len(errorList) == 0 {
+		return nil
+	}
+	sort.Strings(errorList)
+	return errors.New(strings.Join(errorList, ". "))
+}
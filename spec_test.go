@@ -0,0 +1,58 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpec(t *testing.T) {
+	type database struct {
+		Password string
+	}
+	type config struct {
+		Port     int
+		Hosts    []string
+		Level    string `env:"LEVEL"`
+		Debug    bool
+		Database *database
+	}
+	spec := env.NewSpec().
+		Field("Port", "PORT", env.Default("3000"), env.Base(0)).
+		Field("Hosts", "HOSTS", env.Separator(":")).
+		Field("Level", "LOG_LEVEL", env.OneOf("debug", "info")).
+		Field("Debug", "DEBUG", env.RelaxedBool(), env.Trim()).
+		Field("Database.Password", "DB_PASSWORD", env.Required(), env.Secret())
+
+	cfg := config{Database: &database{}}
+	assert.NoError(t, env.Parse(&cfg, env.WithSpec(spec), env.WithLookuper(env.Map{
+		"HOSTS":       "a:b",
+		"LOG_LEVEL":   "debug",
+		"LEVEL":       "ignored",
+		"DEBUG":       " yes ",
+		"DB_PASSWORD": "hunter2",
+	})))
+	assert.Equal(t, config{Port: 3000, Hosts: []string{"a", "b"}, Level: "debug", Debug: true, Database: &database{Password: "hunter2"}}, cfg)
+
+	assert.EqualError(t, env.Parse(&cfg, env.WithSpec(spec), env.WithLookuper(env.Map{"LOG_LEVEL": "trace", "DB_PASSWORD": "x"})),
+		"Invalid value for LOG_LEVEL, expected one of debug, info")
+	assert.EqualError(t, env.Parse(&cfg, env.WithSpec(spec), env.WithLookuper(env.Map{})),
+		"Required environment variable DB_PASSWORD is not set")
+}
+
+func TestSpecErrors(t *testing.T) {
+	type config struct {
+		Port int
+	}
+	spec := env.NewSpec().Field("Prot", "PORT").Field("Port", "PORT", env.Base(1))
+	assert.EqualError(t, env.Parse(&config{}, env.WithSpec(spec), env.WithLookuper(env.Map{})),
+		"Invalid envBase 1, expected 0 or 2 to 36. Field Prot not found")
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		spec = env.NewSpec().Field("Port", "PORT", env.Timeout(d))
+		assert.EqualError(t, env.Parse(&config{}, env.WithSpec(spec), env.WithLookuper(env.Map{})),
+			"Invalid envTimeout "+d.String()+", expected a positive duration")
+	}
+}
@@ -0,0 +1,549 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagInfo holds the settings of a field, read from its struct tags
+type tagInfo struct {
+	key             string
+	defaultValue    string
+	separator       string
+	keyValSeparator string
+	required        bool
+	secret          bool
+	trim            bool
+	relaxedBool     bool
+	// whether the items of lists and maps are trimmed, and empty ones
+	// skipped
+	trimItems bool
+	skipEmpty bool
+	// whether items can be quoted or escaped to contain the separator
+	quoted bool
+	// whether `\n` sequences are replaced by newlines
+	unescapeNewlines bool
+	// command whose output is the value, see `envSource`
+	command []string
+	// variable of the private key of a tls.Certificate
+	privateKey string
+	// prefix of the overrides of Features
+	featurePrefix string
+	// value clearing the field, see `envNullValue`
+	nullValue string
+	// whether durations accept days and weeks
+	extendedDuration bool
+	// default currency of Money fields, and whether amounts are in minor
+	// units
+	currency   string
+	minorUnits bool
+	// normalization of the keys of captured maps
+	stripPrefix bool
+	lowercase   bool
+	dotted      bool
+	// base of integers, or -1 to use the default
+	base int
+	// separators of the items of nested maps, and of their keys in the
+	// outer map, see `envInnerSeparator` and `envOuterKeyValSeparator`
+	innerSeparator       string
+	outerKeyValSeparator string
+	// whether the field can't change when the config is reloaded
+	reloadForbidden bool
+	// allowed values, empty if any value is allowed
+	oneOf []string
+	// timeout of remote lookups, 0 if unbounded
+	timeout time.Duration
+	// interval between refreshes of Refreshing fields, 0 if never
+	refresh time.Duration
+	// prefix of the keys of a nested struct, and whether it has none, which
+	// is the default for embedded structs
+	prefix string
+	inline bool
+	// order of the sources of the value, see `envPriority`, nil for the
+	// order of the parse
+	priority []string
+	// what to do when the value can't be parsed, see `envOnError`; empty
+	// means fail
+	onError string
+	// bounds of numbers, see `envClamp`, empty if unbounded
+	clampMin string
+	clampMax string
+	// bounds of durations, see `envMinDuration` and `envMaxDuration`, nil
+	// if unbounded
+	minDuration *time.Duration
+	maxDuration *time.Duration
+}
+
+// Values of `envOnError`
+const (
+	onErrorFail    = "fail"
+	onErrorIgnore  = "ignore"
+	onErrorDefault = "default"
+)
+
+// TagInfo describes how Parse reads a struct field, as read from its struct
+// tags by ParseTag. It has a field for each `env*` tag and for the required
+// and secret options; the other options of the `env` tag, such as trimItems
+// or extendedDuration, are only listed in Options.
+type TagInfo struct {
+	// Key is the environment variable, empty if the field has none, e.g. a
+	// nested struct
+	Key string
+	// Options follow the key in the `env` tag, e.g. required or trimItems
+	Options []string
+	// Required and Secret are set by the options, and Secret by secret types
+	// such as SecretString as well
+	Required bool
+	Secret   bool
+	Default  string
+	// Separator and KeyValSeparator split lists and maps, `,` and `:` unless
+	// set
+	Separator       string
+	KeyValSeparator string
+	// InnerSeparator and OuterKeyValSeparator split maps of maps, `;` and
+	// `=` unless set
+	InnerSeparator       string
+	OuterKeyValSeparator string
+	// Prefix is prepended to the keys of a nested struct, and Inline keeps
+	// them unprefixed, as for embedded structs
+	Prefix string
+	Inline bool
+	// OneOf lists the allowed values, empty if any value is allowed
+	OneOf []string
+	// Base is the base of integers, -1 for the base of the parse
+	Base int
+	// Timeout bounds remote lookups and Refresh is the interval between
+	// refreshes of Refreshing fields, 0 if unset
+	Timeout time.Duration
+	Refresh time.Duration
+	// Priority orders the sources of the value, nil for the order of the
+	// parse
+	Priority []string
+	// Command is the command whose output is the value, see `envSource`
+	Command []string
+	// NullValue clears the field, see `envNullValue`
+	NullValue string
+	// OnError is what to do when the value can't be parsed, empty to fail
+	OnError string
+	// ReloadForbidden is set when the field can't change on reload
+	ReloadForbidden bool
+	// ClampMin and ClampMax bound numbers, empty if unbounded, and
+	// MinDuration and MaxDuration bound durations, nil if unbounded
+	ClampMin    string
+	ClampMax    string
+	MinDuration *time.Duration
+	MaxDuration *time.Duration
+	// Currency is the default currency of Money fields, see `envCurrency`
+	Currency string
+	// PrivateKey is the variable of the private key of a tls.Certificate,
+	// see `envPrivateKey`
+	PrivateKey string
+	// FeaturePrefix is the prefix of the overrides of Features, see
+	// `envFeaturePrefix`
+	FeaturePrefix string
+}
+
+// ParseTag reads the struct tags of field as Parse does, with the same
+// defaults and validation, so linters and code generators can reason about
+// them as Parse would. Tools reading source code may leave field.Type nil,
+// which skips the checks of the tags against the type, such as `envClamp`
+// on a field that isn't a number.
+func ParseTag(field reflect.StructField) (TagInfo, error) {
+	info, err := parseTag(field)
+	if err != nil {
+		return TagInfo{}, err
+	}
+	var opts []string
+	_, all := parseKeyForOption(field.Tag.Get("env"))
+	for _, opt := range all {
+		if opt != "" {
+			opts = append(opts, opt)
+		}
+	}
+	return TagInfo{
+		Key:                  info.key,
+		Options:              opts,
+		Required:             info.required,
+		Secret:               info.secret,
+		Default:              info.defaultValue,
+		Separator:            info.separator,
+		KeyValSeparator:      info.keyValSeparator,
+		InnerSeparator:       info.innerSeparator,
+		OuterKeyValSeparator: info.outerKeyValSeparator,
+		Prefix:               info.prefix,
+		Inline:               info.inline,
+		OneOf:                info.oneOf,
+		Base:                 info.base,
+		Timeout:              info.timeout,
+		Refresh:              info.refresh,
+		Priority:             info.priority,
+		Command:              info.command,
+		NullValue:            info.nullValue,
+		OnError:              info.onError,
+		ReloadForbidden:      info.reloadForbidden,
+		ClampMin:             info.clampMin,
+		ClampMax:             info.clampMax,
+		MinDuration:          copyDuration(info.minDuration),
+		MaxDuration:          copyDuration(info.maxDuration),
+		Currency:             info.currency,
+		PrivateKey:           info.privateKey,
+		FeaturePrefix:        info.featurePrefix,
+	}, nil
+}
+
+// copyDuration returns a copy of *d, or nil if d is nil
+func copyDuration(d *time.Duration) *time.Duration {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
+func parseTag(field reflect.StructField) (tagInfo, error) {
+	key, opts := parseKeyForOption(field.Tag.Get("env"))
+	info := tagInfo{
+		key:                  key,
+		defaultValue:         field.Tag.Get("envDefault"),
+		separator:            field.Tag.Get("envSeparator"),
+		keyValSeparator:      field.Tag.Get("envKeyValSeparator"),
+		innerSeparator:       field.Tag.Get("envInnerSeparator"),
+		outerKeyValSeparator: field.Tag.Get("envOuterKeyValSeparator"),
+		prefix:               field.Tag.Get("envPrefix"),
+		currency:             field.Tag.Get("envCurrency"),
+		privateKey:           field.Tag.Get("envPrivateKey"),
+		featurePrefix:        field.Tag.Get("envFeaturePrefix"),
+		nullValue:            field.Tag.Get("envNullValue"),
+		base:                 -1,
+	}
+	if info.separator == "" {
+		info.separator = ","
+	}
+	if info.keyValSeparator == "" {
+		info.keyValSeparator = ":"
+	}
+	if info.innerSeparator == "" {
+		info.innerSeparator = ";"
+	}
+	if info.outerKeyValSeparator == "" {
+		info.outerKeyValSeparator = "="
+	}
+
+	if base := field.Tag.Get("envBase"); base != "" {
+		b, err := strconv.Atoi(base)
+		if err != nil || b < 0 || b == 1 || b > 36 {
+			return info, errors.New("Invalid envBase " + base + ", expected 0 or 2 to 36")
+		}
+		info.base = b
+	}
+
+	if timeout := field.Tag.Get("envTimeout"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return info, errors.New("Invalid envTimeout " + timeout + ", expected a positive duration")
+		}
+		info.timeout = d
+	}
+
+	if refresh := field.Tag.Get("envRefresh"); refresh != "" {
+		d, err := time.ParseDuration(refresh)
+		if err != nil || d <= 0 {
+			return info, errors.New("Invalid envRefresh " + refresh + ", expected a positive duration")
+		}
+		info.refresh = d
+	}
+
+	if priority := field.Tag.Get("envPriority"); priority != "" {
+		info.priority = splitPriority(priority)
+	}
+
+	if source := field.Tag.Get("envSource"); source != "" {
+		command, err := parseSource(source)
+		if err != nil {
+			return info, err
+		}
+		info.command = command
+	}
+
+	if clamp := field.Tag.Get("envClamp"); clamp != "" {
+		t := field.Type
+		if t == nil {
+			// unknown to tools reading source code, see ParseTag
+			t = reflect.TypeOf(float64(0))
+		}
+		lo, hi, err := parseClamp(clamp, t)
+		if err != nil {
+			return info, err
+		}
+		info.clampMin, info.clampMax = lo, hi
+	}
+
+	for _, bound := range []struct {
+		tag string
+		d   **time.Duration
+	}{{"envMinDuration", &info.minDuration}, {"envMaxDuration", &info.maxDuration}} {
+		value := field.Tag.Get(bound.tag)
+		if value == "" {
+			continue
+		}
+		if t := field.Type; t != nil && t != durationType && (t.Kind() != reflect.Ptr || t.Elem() != durationType) {
+			return info, errors.New("Invalid " + bound.tag + " " + value + ", the field isn't a duration")
+		}
+		// whether the field accepts days and weeks is only known once the
+		// options are given, so the bounds always do
+		v, err := parseExtendedDuration(value)
+		if err != nil {
+			return info, errors.New("Invalid " + bound.tag + " " + value + ", expected a duration")
+		}
+		d := v.Interface().(time.Duration)
+		*bound.d = &d
+	}
+	if info.minDuration != nil && info.maxDuration != nil && *info.minDuration > *info.maxDuration {
+		return info, errors.New("Invalid envMinDuration " + info.minDuration.String() + ", greater than envMaxDuration " + info.maxDuration.String())
+	}
+
+	if oneOf := field.Tag.Get("envOneOf"); oneOf != "" {
+		info.oneOf = strings.Split(oneOf, ",")
+	}
+
+	switch inline := field.Tag.Get("envInline"); inline {
+	case "":
+		// embedded structs share the prefix of their parent, like mapstructure's squash
+		info.inline = field.Anonymous && info.prefix == ""
+	case "false":
+	case "true":
+		if info.prefix != "" {
+			return info, errors.New("Field " + field.Name + " can't have both envPrefix and envInline")
+		}
+		info.inline = true
+	default:
+		return info, errors.New("Invalid envInline " + inline + ", expected true or false")
+	}
+
+	switch info.onError = field.Tag.Get("envOnError"); info.onError {
+	case "", onErrorFail, onErrorIgnore, onErrorDefault:
+	default:
+		return info, errors.New("Invalid envOnError " + info.onError + ", expected ignore, default or fail")
+	}
+
+	switch reload := field.Tag.Get("envReload"); reload {
+	case "", "allow":
+	case "forbid":
+		info.reloadForbidden = true
+	default:
+		return info, errors.New("Invalid envReload " + reload + ", expected allow or forbid")
+	}
+
+	for _, opt := range opts {
+		switch opt {
+		case "":
+			break
+		case "required":
+			info.required = true
+		case "secret":
+			info.secret = true
+		case "trim":
+			info.trim = true
+		case "relaxedBool":
+			info.relaxedBool = true
+		case "extendedDuration":
+			info.extendedDuration = true
+		case "trimItems":
+			info.trimItems = true
+		case "skipEmpty":
+			info.skipEmpty = true
+		case "quoted":
+			info.quoted = true
+		case "unescapeNewlines":
+			info.unescapeNewlines = true
+		case "minorUnits":
+			info.minorUnits = true
+		case "stripPrefix":
+			info.stripPrefix = true
+		case "lowercase":
+			info.lowercase = true
+		case "dotted":
+			info.dotted = true
+		default:
+			return info, errors.New("Env tag option " + opt + " not supported.")
+		}
+	}
+	info.secret = info.secret || isSecretType(field.Type)
+	return info, nil
+}
+
+// split the env tag's key into the expected key and desired option, if any.
+func parseKeyForOption(key string) (string, []string) {
+	opts := strings.Split(key, ",")
+	return opts[0], opts[1:]
+}
+
+// checkOneOf validates value, the value of a field of type t, against the
+// values allowed by `envOneOf`. The items of slices are validated one by one.
+func (info tagInfo) checkOneOf(value string, t reflect.Type) error {
+	if len(info.oneOf) == 0 {
+		return nil
+	}
+	items := []string{value}
+	if t.Kind() == reflect.Slice {
+		var err error
+		if items, err = info.splitItems(value); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		if !hasOption(info.oneOf, item) {
+			return errors.New("Invalid value for " + info.key + ", expected one of " + strings.Join(info.oneOf, ", "))
+		}
+	}
+	return nil
+}
+
+// checkDuration validates the duration in field, parsed from value,
+// against the bounds of `envMinDuration` and `envMaxDuration`
+func (info tagInfo) checkDuration(field reflect.Value, value string) error {
+	if info.minDuration == nil && info.maxDuration == nil {
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	d := time.Duration(field.Int())
+	if (info.minDuration == nil || d >= *info.minDuration) && (info.maxDuration == nil || d <= *info.maxDuration) {
+		return nil
+	}
+	var expected string
+	switch {
+	case info.maxDuration == nil:
+		expected = "at least " + info.minDuration.String()
+	case info.minDuration == nil:
+		expected = "at most " + info.maxDuration.String()
+	default:
+		expected = "between " + info.minDuration.String() + " and " + info.maxDuration.String()
+	}
+	return errors.New("Invalid duration " + value + " for " + info.key + ", expected " + expected)
+}
+
+// splitItems splits the value of a list or map into its items, trimmed and
+// without empty ones if the field says so
+func (info tagInfo) splitItems(value string) ([]string, error) {
+	return info.tokenize(value, info.quoted)
+}
+
+// splitPairs splits the value of a map into its keys and values. With
+// `quoted`, separators inside quotes are part of the key or value, e.g.
+// `"a:b":c` has the key `a:b`.
+func (info tagInfo) splitPairs(value string) ([][2]string, error) {
+	items, err := info.tokenize(value, false)
+	if err != nil {
+		return nil, err
+	}
+	sep := info.keyValSeparator
+	if sep == "" {
+		sep = ":"
+	}
+	pairs := make([][2]string, 0, len(items))
+	for _, item := range items {
+		var pair []string
+		if info.quoted {
+			// quotes were checked when splitting the items
+			pair, _ = splitQuoted(item, sep, 2, true)
+		} else {
+			pair = strings.SplitN(item, sep, 2)
+		}
+		if len(pair) != 2 {
+			return nil, errors.New("Invalid map item: " + item)
+		}
+		if info.trimItems {
+			pair[0], pair[1] = strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+		}
+		pairs = append(pairs, [2]string{pair[0], pair[1]})
+	}
+	return pairs, nil
+}
+
+// tokenize splits value into items, on the separator outside of quotes with
+// `quoted`, removing the quotes if unquote is set, and trims and skips items
+// as the field says
+func (info tagInfo) tokenize(value string, unquote bool) ([]string, error) {
+	sep := info.separator
+	if sep == "" {
+		// an empty separator would split every character, or never end
+		sep = ","
+	}
+	var items []string
+	if info.quoted {
+		var err error
+		if items, err = splitQuoted(value, sep, -1, unquote); err != nil {
+			return nil, err
+		}
+	} else {
+		items = strings.Split(value, sep)
+	}
+	if !info.trimItems && !info.skipEmpty {
+		return items, nil
+	}
+	kept := items[:0]
+	for _, item := range items {
+		if info.trimItems {
+			item = strings.TrimSpace(item)
+		}
+		if info.skipEmpty && strings.TrimSpace(item) == "" {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, nil
+}
+
+// splitQuoted splits value on sep, except inside double quotes or after a
+// backslash, like CSV: `"a,b",c\,d` is split into `a,b` and `c,d`. Inside
+// quotes, a doubled quote stands for a quote. Like strings.SplitN, it returns
+// at most n items if n > 0. Unless unquote is set, the quotes and
+// backslashes are kept, so the items can be split again. sep can't be empty.
+func splitQuoted(value, sep string, n int, unquote bool) ([]string, error) {
+	var (
+		items    []string
+		item     strings.Builder
+		inQuotes bool
+	)
+	for i := 0; i < len(value); {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			if !unquote {
+				item.WriteByte(c)
+			}
+			item.WriteByte(value[i+1])
+			i += 2
+		case c == '"' && inQuotes && i+1 < len(value) && value[i+1] == '"':
+			if !unquote {
+				item.WriteByte(c)
+			}
+			item.WriteByte('"')
+			i += 2
+		case c == '"':
+			if !unquote {
+				item.WriteByte(c)
+			}
+			inQuotes = !inQuotes
+			i++
+		case !inQuotes && (n <= 0 || len(items) < n-1) && strings.HasPrefix(value[i:], sep):
+			items = append(items, item.String())
+			item.Reset()
+			i += len(sep)
+		default:
+			item.WriteByte(c)
+			i++
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("Unterminated quote in " + value)
+	}
+	return append(items, item.String()), nil
+}
@@ -0,0 +1,104 @@
+package env_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	type database struct {
+		Host string `env:"HOST"`
+	}
+	type config struct {
+		Hosts    []string         `env:"HOSTS,required,trimItems," envSeparator:"|" envDefault:"a|b"`
+		Token    env.SecretString `env:"TOKEN"`
+		Level    string           `env:"LEVEL" envOneOf:"debug,info" envOnError:"default"`
+		Timeout  time.Duration    `env:"TIMEOUT" envMaxDuration:"1m" envReload:"forbid"`
+		Database database         `envPrefix:"DB_"`
+		Port     int              `env:"PORT" envBase:"8"`
+		Price    env.Money        `env:"PRICE" envCurrency:"EUR"`
+		Cert     tls.Certificate  `env:"CERT" envPrivateKey:"CERT_KEY"`
+		Flags    env.Features     `env:"FLAGS" envFeaturePrefix:"FLAG_"`
+		Invalid  int              `env:"INVALID,bogus"`
+	}
+	field := func(name string) reflect.StructField {
+		f, _ := reflect.TypeOf(config{}).FieldByName(name)
+		return f
+	}
+
+	info, err := env.ParseTag(field("Hosts"))
+	assert.NoError(t, err)
+	assert.Equal(t, env.TagInfo{
+		Key:                  "HOSTS",
+		Options:              []string{"required", "trimItems"},
+		Required:             true,
+		Default:              "a|b",
+		Separator:            "|",
+		KeyValSeparator:      ":",
+		InnerSeparator:       ";",
+		OuterKeyValSeparator: "=",
+		Base:                 -1,
+	}, info)
+
+	info, err = env.ParseTag(field("Token"))
+	assert.NoError(t, err)
+	assert.True(t, info.Secret)
+	assert.Nil(t, info.Options)
+
+	info, err = env.ParseTag(field("Level"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"debug", "info"}, info.OneOf)
+	assert.Equal(t, "default", info.OnError)
+
+	info, err = env.ParseTag(field("Timeout"))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, *info.MaxDuration)
+	assert.Nil(t, info.MinDuration)
+	assert.True(t, info.ReloadForbidden)
+	*info.MaxDuration = time.Hour
+	info, _ = env.ParseTag(field("Timeout"))
+	assert.Equal(t, time.Minute, *info.MaxDuration)
+
+	info, err = env.ParseTag(field("Database"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", info.Key)
+	assert.Equal(t, "DB_", info.Prefix)
+
+	info, err = env.ParseTag(field("Port"))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, info.Base)
+
+	info, err = env.ParseTag(field("Price"))
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", info.Currency)
+
+	info, err = env.ParseTag(field("Cert"))
+	assert.NoError(t, err)
+	assert.Equal(t, "CERT_KEY", info.PrivateKey)
+
+	info, err = env.ParseTag(field("Flags"))
+	assert.NoError(t, err)
+	assert.Equal(t, "FLAG_", info.FeaturePrefix)
+
+	_, err = env.ParseTag(field("Invalid"))
+	assert.Equal(t, errors.New("Env tag option bogus not supported."), err)
+}
+
+func TestParseTagWithoutType(t *testing.T) {
+	info, err := env.ParseTag(reflect.StructField{
+		Name: "Port",
+		Tag:  `env:"PORT" envClamp:"1:65535" envMinDuration:"1s"`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", info.ClampMin)
+	assert.Equal(t, time.Second, *info.MinDuration)
+
+	_, err = env.ParseTag(reflect.StructField{Name: "Port", Tag: `env:"PORT" envClamp:"5:1"`})
+	assert.Equal(t, errors.New("Invalid envClamp 5:1, expected min <= max"), err)
+}
@@ -0,0 +1,42 @@
+package env
+
+import (
+	"context"
+	"fmt"
+)
+
+// Tracer starts spans around Parse and around each lookup from a
+// ContextLookuper, so startup latency can be attributed to its sources.
+// See the envotel package for an OpenTelemetry implementation.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// WithTracer creates spans for the parse using t
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// startSpan starts a span as a child of o.ctx, if tracing is enabled. The
+// returned context should be used for the work the span covers.
+func (o *options) startSpan(name string) (context.Context, Span) {
+	if o.tracer == nil {
+		return o.ctx, nopSpan{}
+	}
+	ctx, span := o.tracer.Start(o.ctx, name)
+	span.SetAttribute("env.source", fmt.Sprintf("%T", o.lookuper))
+	return ctx, span
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(key string, value interface{}) {}
+func (nopSpan) End(err error)                              {}
@@ -0,0 +1,81 @@
+package env_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, env.Span) {
+	s := &recordingSpan{name: name, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordingSpan) End(err error)                              { s.err, s.ended = err, true }
+
+func TestTracer(t *testing.T) {
+	type config struct {
+		Var  string `env:"VAR"`
+		Port int    `env:"PORT"`
+	}
+	tracer := &recordingTracer{}
+	cfg := config{}
+	err := env.Parse(&cfg, env.WithTracer(tracer), env.WithLookuper(&flakyLookuper{}))
+	assert.Error(t, err)
+
+	assert.Len(t, tracer.spans, 3)
+	parse := tracer.spans[0]
+	assert.Equal(t, "env.Parse", parse.name)
+	assert.True(t, parse.ended)
+	assert.Equal(t, err, parse.err)
+	assert.Equal(t, "*env_test.flakyLookuper", parse.attrs["env.source"])
+	assert.Equal(t, 1, parse.attrs["env.fields"])
+	assert.Equal(t, 1, parse.attrs["env.errors"])
+
+	lookup := tracer.spans[1]
+	assert.Equal(t, "env.Lookup", lookup.name)
+	assert.Equal(t, "VAR", lookup.attrs["env.key"])
+	assert.True(t, lookup.ended)
+}
+
+func TestTracerCountsNestedErrors(t *testing.T) {
+	type database struct {
+		Host string `env:"HOST,required"`
+		User string `env:"USER,required"`
+	}
+	type config struct {
+		Database database      `envPrefix:"DB_"`
+		Replica  *database     `envPrefix:"REPLICA_"`
+		Storage  storageConfig `env:"STORAGE_DRIVER" envPrefix:"STORAGE_"`
+		Port     int           `env:"PORT,required"`
+	}
+	tracer := &recordingTracer{}
+	err := env.Parse(&config{Replica: &database{}}, env.WithTracer(tracer), env.WithLookuper(env.Map{
+		"REPLICA_HOST":   "db",
+		"STORAGE_DRIVER": "s3",
+	}))
+	assert.Error(t, err)
+	assert.Equal(t, 5, tracer.spans[0].attrs["env.errors"])
+
+	tracer = &recordingTracer{}
+	assert.Error(t, env.Parse(&config{Replica: &database{}}, env.WithTracer(tracer), env.WithLookuper(env.Map{
+		"DB_HOST": "db", "DB_USER": "app", "REPLICA_HOST": "db", "REPLICA_USER": "app", "PORT": "80",
+		"STORAGE_DRIVER": "gcs",
+	})))
+	assert.Equal(t, 1, tracer.spans[0].attrs["env.errors"])
+}
@@ -0,0 +1,42 @@
+package env
+
+import "strings"
+
+// KeyTransform rewrites the key of a field, after its prefixes are applied,
+// before it is looked up, so that tags written in one convention can be read
+// from environments populated in another, see `WithKeyTransform`
+type KeyTransform func(key string) string
+
+// Built-in KeyTransforms
+var (
+	// UpperKeys converts keys to upper case, e.g. `db_host` to `DB_HOST`
+	UpperKeys KeyTransform = strings.ToUpper
+	// DotsToUnderscores replaces dots by underscores, e.g. `db.host` to
+	// `db_host`
+	DotsToUnderscores KeyTransform = func(key string) string {
+		return strings.Replace(key, ".", "_", -1)
+	}
+	// KebabToSnake replaces dashes by underscores, e.g. `db-host` to
+	// `db_host`
+	KebabToSnake KeyTransform = func(key string) string {
+		return strings.Replace(key, "-", "_", -1)
+	}
+)
+
+// WithKeyTransform rewrites the keys of all fields with the given
+// transforms, applied in order, e.g. `WithKeyTransform(env.DotsToUnderscores,
+// env.UpperKeys)` reads `db.host` from `DB_HOST`. Errors and reports show
+// the rewritten keys.
+func WithKeyTransform(transforms ...KeyTransform) Option {
+	return func(o *options) {
+		o.transforms = append(o.transforms, transforms...)
+	}
+}
+
+// transformKey applies the key transforms to key
+func (o *options) transformKey(key string) string {
+	for _, t := range o.transforms {
+		key = t(key)
+	}
+	return key
+}
@@ -0,0 +1,35 @@
+package env_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyTransform(t *testing.T) {
+	type database struct {
+		Host string `env:"host,required"`
+	}
+	type config struct {
+		Database *database `envPrefix:"db."`
+		LogLevel string    `env:"log-level"`
+	}
+	cfg := config{Database: &database{}}
+	transform := env.WithKeyTransform(env.DotsToUnderscores, env.KebabToSnake, env.UpperKeys)
+	assert.NoError(t, env.Parse(&cfg, transform, env.WithParallelLookups(2), env.WithLookuper(env.Map{
+		"DB_HOST":   "localhost",
+		"LOG_LEVEL": "debug",
+	})))
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, "debug", cfg.LogLevel)
+
+	err := env.Parse(&cfg, transform, env.WithLookuper(env.Map{}))
+	assert.Equal(t, errors.New("Required environment variable DB_HOST is not set"), err)
+
+	lower := env.WithKeyTransform(strings.ToLower)
+	assert.NoError(t, env.Parse(&cfg, lower, env.WithLookuper(env.Map{"db.host": "remote"})))
+	assert.Equal(t, "remote", cfg.Database.Host)
+}
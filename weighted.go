@@ -0,0 +1,53 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Weighted is a value and its weight, read from `value:weight`, e.g.
+// `BACKENDS=a:3,b:1,c:1` into a `[]env.Weighted[string]` for traffic
+// splitting. The value is parsed like a field of type T. The weight is a
+// non-negative integer, 1 if omitted: `host:port` is read as a value, but a
+// value ending with `:` and a number, such as `host:8080`, must be followed by
+// its weight.
+type Weighted[T any] struct {
+	Value  T
+	Weight int
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (w *Weighted[T]) UnmarshalText(text []byte) error {
+	return w.unmarshalWith(string(text), defaultConversion())
+}
+
+func (w *Weighted[T]) unmarshalWith(text string, c *conversion) error {
+	s, weight := text, 1
+	// the value may contain colons, e.g. host:port:weight, and the weight
+	// is only taken if it is a number
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(s[i+1:])); err == nil {
+			if n < 0 {
+				return errors.New("Invalid weight in " + text + ", expected a non-negative integer")
+			}
+			s, weight = s[:i], n
+		}
+	}
+	var parsed Weighted[T]
+	v, err := convert(s, reflect.TypeOf(&parsed.Value).Elem(), c)
+	if err != nil {
+		return err
+	}
+	parsed.Value, parsed.Weight = v.Interface().(T), weight
+	*w = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, writing `value:weight`
+// with the value formatted by fmt.Sprint
+func (w Weighted[T]) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprint(w.Value) + ":" + strconv.Itoa(w.Weight)), nil
+}
@@ -0,0 +1,50 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeighted(t *testing.T) {
+	type config struct {
+		Backends []env.Weighted[string] `env:"BACKENDS"`
+		Shards   []env.Weighted[int]    `env:"SHARDS"`
+		Hosts    []env.Weighted[string] `env:"HOSTS" envSeparator:" "`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"BACKENDS": "a:3,b:1,c",
+		"SHARDS":   "1:0,2:10",
+		"HOSTS":    "db1:5432:2 db2:5432:1",
+	})))
+	assert.Equal(t, []env.Weighted[string]{{Value: "a", Weight: 3}, {Value: "b", Weight: 1}, {Value: "c", Weight: 1}}, cfg.Backends)
+	assert.Equal(t, []env.Weighted[int]{{Value: 1, Weight: 0}, {Value: 2, Weight: 10}}, cfg.Shards)
+	assert.Equal(t, []env.Weighted[string]{{Value: "db1:5432", Weight: 2}, {Value: "db2:5432", Weight: 1}}, cfg.Hosts)
+
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"BACKENDS": "a:-1"}))
+	assert.Equal(t, errors.New("Invalid weight in a:-1, expected a non-negative integer"), err)
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"SHARDS": "x:1"}))
+	assert.Error(t, err)
+}
+
+func TestWeightedParsedLikeFields(t *testing.T) {
+	type config struct {
+		Timeouts []env.Weighted[time.Duration] `env:"TIMEOUTS"`
+		Hosts    []env.Weighted[string]        `env:"HOSTS"`
+	}
+	cfg := config{}
+	assert.NoError(t, env.Parse(&cfg, env.WithLookuper(env.Map{
+		"TIMEOUTS": "1s:2,1m",
+		"HOSTS":    "db1:postgres,db2:postgres:3",
+	})))
+	assert.Equal(t, []env.Weighted[time.Duration]{{Value: time.Second, Weight: 2}, {Value: time.Minute, Weight: 1}}, cfg.Timeouts)
+	assert.Equal(t, []env.Weighted[string]{{Value: "db1:postgres", Weight: 1}, {Value: "db2:postgres", Weight: 3}}, cfg.Hosts)
+
+	var w env.Weighted[time.Duration]
+	assert.NoError(t, w.UnmarshalText([]byte("500ms:4")))
+	assert.Equal(t, env.Weighted[time.Duration]{Value: 500 * time.Millisecond, Weight: 4}, w)
+}